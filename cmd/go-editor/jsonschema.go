@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/confluentinc/go-editor"
+)
+
+// jsonSchemaFile validates JSON against a JSON Schema. It supports the commonly
+// used subset of keywords: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength,
+// pattern, minimum and maximum. Schemas using other validation keywords are
+// rejected, rather than accepting data they would reject.
+type jsonSchemaFile struct {
+	root *schemaNode
+}
+
+type schemaNode struct {
+	Type                 schemaTypes            `json:"type"`
+	Enum                 []any                  `json:"enum"`
+	Const                schemaConst            `json:"const"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *additionalProperties  `json:"additionalProperties"`
+	Items                *schemaNode            `json:"items"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	Minimum              *json.Number           `json:"minimum"`
+	Maximum              *json.Number           `json:"maximum"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaTypes is a type name or a list of them.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = schemaTypes{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return errors.New("type must be a string or a list of strings")
+	}
+	*t = names
+	return nil
+}
+
+// additionalProperties is a boolean or a schema.
+type additionalProperties struct {
+	Allowed bool
+	Schema  *schemaNode
+}
+
+func (a *additionalProperties) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.Allowed); err == nil {
+		return nil
+	}
+	a.Allowed = true
+	return json.Unmarshal(data, &a.Schema)
+}
+
+// schemaConst is a value the data must equal, which may be null.
+type schemaConst struct {
+	Set   bool
+	Value any
+}
+
+func (c *schemaConst) UnmarshalJSON(data []byte) error {
+	c.Set = true
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(&c.Value)
+}
+
+// loadJSONSchema reads a JSON Schema from a file.
+func loadJSONSchema(file string) (*jsonSchemaFile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := parseJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("reading JSON Schema %s: %w", file, err)
+	}
+	if err := checkKeywords(raw, "#"); err != nil {
+		return nil, fmt.Errorf("reading JSON Schema %s: %w", file, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root := &schemaNode{}
+	if err := dec.Decode(root); err != nil {
+		return nil, fmt.Errorf("reading JSON Schema %s: %w", file, err)
+	}
+	if err := root.compile(); err != nil {
+		return nil, fmt.Errorf("reading JSON Schema %s: %w", file, err)
+	}
+	return &jsonSchemaFile{root: root}, nil
+}
+
+// unsupportedKeywords are the validation keywords of JSON Schema which
+// schemaNode doesn't implement.
+var unsupportedKeywords = map[string]bool{
+	"$ref": true, "$dynamicRef": true, "$recursiveRef": true,
+	"allOf": true, "anyOf": true, "oneOf": true, "not": true,
+	"if": true, "then": true, "else": true,
+	"dependencies": true, "dependentRequired": true, "dependentSchemas": true,
+	"patternProperties": true, "propertyNames": true, "minProperties": true, "maxProperties": true,
+	"unevaluatedProperties": true, "unevaluatedItems": true,
+	"prefixItems": true, "additionalItems": true, "contains": true, "minContains": true, "maxContains": true,
+	"uniqueItems": true, "exclusiveMinimum": true, "exclusiveMaximum": true, "multipleOf": true,
+	"format": true,
+}
+
+// checkKeywords returns an error if the schema at path, or one of its
+// subschemas, uses an unsupported keyword.
+func checkKeywords(schema any, path string) error {
+	node, ok := schema.(map[string]any)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if unsupportedKeywords[key] {
+			return fmt.Errorf("%s: unsupported keyword %q", path, key)
+		}
+	}
+	if props, ok := node["properties"].(map[string]any); ok {
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := checkKeywords(props[name], path+"/properties/"+name); err != nil {
+				return err
+			}
+		}
+	}
+	if err := checkKeywords(node["additionalProperties"], path+"/additionalProperties"); err != nil {
+		return err
+	}
+	return checkKeywords(node["items"], path+"/items")
+}
+
+// compile prepares the patterns of the schema and its subschemas.
+func (n *schemaNode) compile() error {
+	if n == nil {
+		return nil
+	}
+	if n.Pattern != "" {
+		re, err := regexp.Compile(n.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern %q: %w", n.Pattern, err)
+		}
+		n.pattern = re
+	}
+	for _, p := range n.Properties {
+		if err := p.compile(); err != nil {
+			return err
+		}
+	}
+	if n.AdditionalProperties != nil {
+		if err := n.AdditionalProperties.Schema.compile(); err != nil {
+			return err
+		}
+	}
+	return n.Items.compile()
+}
+
+func (s *jsonSchemaFile) ValidateBytes(data []byte) error {
+	v, err := parseJSON(data)
+	if err != nil {
+		return err
+	}
+	return errors.Join(s.root.validate(v, "")...)
+}
+
+// validate returns an error for each violation of the schema by the value at path.
+func (n *schemaNode) validate(v any, path string) []error {
+	if n == nil {
+		return nil
+	}
+	fail := func(format string, args ...any) error {
+		where := path
+		if where == "" {
+			where = "(root)"
+		}
+		return fmt.Errorf("%s: %s", where, fmt.Sprintf(format, args...))
+	}
+
+	if len(n.Type) > 0 && !n.Type.matches(v) {
+		return []error{fail("must be %s, not %s", strings.Join(n.Type, " or "), typeOf(v))}
+	}
+	var errs []error
+	if len(n.Enum) > 0 && !containsValue(n.Enum, v) {
+		errs = append(errs, fail("must be one of %s", formatValues(n.Enum)))
+	}
+	if n.Const.Set && !editor.JSONValuesEqual(n.Const.Value, v) {
+		errs = append(errs, fail("must be %s", formatValues([]any{n.Const.Value})))
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range n.Required {
+			if _, ok := v[name]; !ok {
+				errs = append(errs, fail("missing required property %q", name))
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if p, ok := n.Properties[name]; ok {
+				errs = append(errs, p.validate(v[name], path+"."+name)...)
+				continue
+			}
+			if a := n.AdditionalProperties; a != nil {
+				if !a.Allowed {
+					errs = append(errs, fail("unknown property %q", name))
+				} else {
+					errs = append(errs, a.Schema.validate(v[name], path+"."+name)...)
+				}
+			}
+		}
+	case []any:
+		if n.MinItems != nil && len(v) < *n.MinItems {
+			errs = append(errs, fail("must have at least %d items", *n.MinItems))
+		}
+		if n.MaxItems != nil && len(v) > *n.MaxItems {
+			errs = append(errs, fail("must have at most %d items", *n.MaxItems))
+		}
+		for i, item := range v {
+			errs = append(errs, n.Items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if n.MinLength != nil && length < *n.MinLength {
+			errs = append(errs, fail("must be at least %d characters", *n.MinLength))
+		}
+		if n.MaxLength != nil && length > *n.MaxLength {
+			errs = append(errs, fail("must be at most %d characters", *n.MaxLength))
+		}
+		if n.pattern != nil && !n.pattern.MatchString(v) {
+			errs = append(errs, fail("must match %q", n.Pattern))
+		}
+	case json.Number:
+		if n.Minimum != nil && compareNumbers(v, *n.Minimum) < 0 {
+			errs = append(errs, fail("must be at least %s", *n.Minimum))
+		}
+		if n.Maximum != nil && compareNumbers(v, *n.Maximum) > 0 {
+			errs = append(errs, fail("must be at most %s", *n.Maximum))
+		}
+	}
+	return errs
+}
+
+func (t schemaTypes) matches(v any) bool {
+	actual := typeOf(v)
+	for _, name := range t {
+		if name == actual || name == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type of a decoded value.
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Number:
+		if r, ok := new(big.Rat).SetString(v.String()); ok && r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func compareNumbers(a, b json.Number) int {
+	x, okX := new(big.Rat).SetString(a.String())
+	y, okY := new(big.Rat).SetString(b.String())
+	if !okX || !okY {
+		return 0
+	}
+	return x.Cmp(y)
+}
+
+func containsValue(values []any, v any) bool {
+	for _, value := range values {
+		if editor.JSONValuesEqual(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValues(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		data, _ := json.Marshal(v)
+		parts[i] = string(data)
+	}
+	return strings.Join(parts, ", ")
+}
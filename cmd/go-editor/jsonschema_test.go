@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["name", "replicas"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "maxLength": 8, "pattern": "^[a-z]+$"},
+		"replicas": {"type": "integer", "minimum": 1, "maximum": 5},
+		"mode": {"enum": ["fast", "safe"]},
+		"version": {"const": 2},
+		"ratio": {"type": ["number", "null"]},
+		"tags": {"type": "array", "minItems": 1, "maxItems": 2, "items": {"type": "string"}},
+		"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+		"defaults": {"const": {"x": null}},
+		"unset": {"const": null}
+	}
+}`
+
+func TestJSONSchemaFile_ValidateBytes(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(file, []byte(testSchema), 0600); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := loadJSONSchema(file)
+	if err != nil {
+		t.Fatalf("loadJSONSchema() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr []string
+	}{
+		{name: "valid", data: `{"name": "app", "replicas": 3, "mode": "safe", "version": 2.0, "ratio": null, "tags": ["a"], "labels": {"a": "b"}, "unset": null}`},
+		{name: "syntax error", data: "{\n  \"name\": ,\n}", wantErr: []string{"line 2, column 11"}},
+		{name: "wrong root type", data: `[]`, wantErr: []string{"(root): must be object, not array"}},
+		{
+			name: "missing and unknown properties",
+			data: `{"name": "app", "other": 1}`,
+			wantErr: []string{
+				`(root): missing required property "replicas"`,
+				`(root): unknown property "other"`,
+			},
+		},
+		{
+			name: "constraints",
+			data: `{"name": "App-Name-Too-Long", "replicas": 1.5, "mode": "slow", "version": 3, "tags": [], "labels": {"a": 1}}`,
+			wantErr: []string{
+				".name: must be at most 8 characters",
+				`.name: must match "^[a-z]+$"`,
+				".replicas: must be integer, not number",
+				`.mode: must be one of "fast", "safe"`,
+				".version: must be 2",
+				".tags: must have at least 1 items",
+				".labels.a: must be string, not integer",
+			},
+		},
+		{name: "object const", data: `{"name": "app", "replicas": 1, "defaults": {"y": null}}`, wantErr: []string{`.defaults: must be {"x":null}`}},
+		{name: "null const", data: `{"name": "app", "replicas": 1, "unset": 0}`, wantErr: []string{".unset: must be null"}},
+		{name: "range", data: `{"name": "app", "replicas": 6}`, wantErr: []string{".replicas: must be at most 5"}},
+		{name: "items", data: `{"name": "app", "replicas": 1, "tags": ["a", 2, "c"]}`, wantErr: []string{".tags: must have at most 2 items", ".tags[1]: must be string"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.ValidateBytes([]byte(tt.data))
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("ValidateBytes() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("ValidateBytes() succeeded, want %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("ValidateBytes() error = %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadJSONSchema_Errors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		schema  string
+		wantErr string
+	}{
+		"invalid JSON":    {schema: `{`},
+		"invalid type":    {schema: `{"type": 1}`},
+		"invalid pattern": {schema: `{"properties": {"a": {"pattern": "("}}}`},
+		"$ref": {
+			schema:  `{"$ref": "#/$defs/a", "$defs": {"a": {"type": "string"}}}`,
+			wantErr: `#: unsupported keyword "$ref"`,
+		},
+		"nested anyOf": {
+			schema:  `{"properties": {"a": {"items": {"anyOf": [{"type": "string"}]}}}}`,
+			wantErr: `#/properties/a/items: unsupported keyword "anyOf"`,
+		},
+		"format": {
+			schema:  `{"additionalProperties": {"format": "email"}}`,
+			wantErr: `#/additionalProperties: unsupported keyword "format"`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "schema.json")
+			if err := os.WriteFile(file, []byte(tt.schema), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := loadJSONSchema(file)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadJSONSchema() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
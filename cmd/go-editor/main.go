@@ -0,0 +1,165 @@
+// Command go-editor lets the user edit its standard input in their preferred
+// editor and writes the result to its standard output, like vipe:
+//
+//	kubectl get configmap app -o json | go-editor -ext .json -json | kubectl apply -f -
+//
+// The editor is reopened until the edited data passes the requested validation.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+
+	"github.com/confluentinc/go-editor"
+)
+
+func main() {
+	in, out := os.Stdin, os.Stdout
+	// the editor needs the terminal, as stdin and stdout are usually piped
+	if ttyIn, ttyOut, err := openTerminal(); err == nil {
+		os.Stdin, os.Stdout = ttyIn, ttyOut
+	}
+	os.Exit(run(os.Args[1:], in, out, os.Stderr))
+}
+
+// openTerminal opens the terminal for reading and writing.
+func openTerminal() (*os.File, *os.File, error) {
+	if runtime.GOOS == "windows" {
+		in, err := os.Open("CONIN$")
+		if err != nil {
+			return nil, nil, err
+		}
+		out, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0)
+		if err != nil {
+			in.Close()
+			return nil, nil, err
+		}
+		return in, out, nil
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	return tty, tty, err
+}
+
+// run edits the data read from stdin and writes it to stdout. Returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("go-editor", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var (
+		command       = flags.String("editor", "", "editor `command`, instead of $VISUAL or $EDITOR")
+		ext           = flags.String("ext", "", "file `extension` of the edited data, like .yaml, for syntax highlighting and comments")
+		stripComments = flags.Bool("strip-comments", false, "remove comments from the edited data")
+		validJSON     = flags.Bool("json", false, "require valid JSON")
+		schemaFile    = flags.String("schema", "", "require JSON valid against the JSON Schema in `file`")
+		maxAttempts   = flags.Int("max-attempts", 0, "give up after `n` invalid attempts, 0 for no limit")
+		validator     = flags.String("validator", "", "validate with `command`, which receives the data on stdin, or as a file in place of {}, and fails if it is invalid")
+		formats       []string
+	)
+	flags.Func("validator-format", "parse the validator output with errorformat `pattern`, like \"%f:%l:%c: %m\" (repeatable)", func(s string) error {
+		formats = append(formats, s)
+		return nil
+	})
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: go-editor [flags] < input > output\n\nEdits stdin in your editor and writes the result to stdout.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var schemas allSchemas
+	if *validJSON {
+		schemas = append(schemas, jsonSchema{})
+	}
+	if *schemaFile != "" {
+		s, err := loadJSONSchema(*schemaFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "go-editor: %v\n", err)
+			return 2
+		}
+		schemas = append(schemas, s)
+	}
+	if *validator != "" {
+		schemas = append(schemas, &editor.CommandSchema{Command: *validator, Extension: *ext, Patterns: formats})
+	}
+
+	input, err := io.ReadAll(stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "go-editor: %v\n", err)
+		return 1
+	}
+
+	edit := editor.NewValidatingEditor(schemas)
+	if *command != "" {
+		edit.Command = *command
+	}
+	edit.Reporter = editor.NewTextReporter(os.Stdin, stderr, stderr)
+	edit.ErrorHeader = true
+	// pass the data through, even if it is unchanged or empty
+	edit.OriginalUnchangedFn = func() (bool, error) { return false, nil }
+	edit.EmptyFileFn = func() (bool, error) { return false, nil }
+	if syntax, ok := editor.CommentSyntaxFor(*ext); ok {
+		edit.CommentSyntax = &syntax
+	}
+	edit.StripComments = *stripComments
+	edit.MaxAttempts = *maxAttempts
+
+	data, file, err := edit.LaunchTempFile("go-editor-*"+*ext, bytes.NewReader(input))
+	if err != nil {
+		fmt.Fprintf(stderr, "go-editor: %v\n", err)
+		return 1
+	}
+	os.Remove(file)
+	if _, err := stdout.Write(data); err != nil {
+		fmt.Fprintf(stderr, "go-editor: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// allSchemas requires data to be valid against each schema in turn.
+type allSchemas []editor.Schema
+
+func (s allSchemas) ValidateBytes(data []byte) error {
+	for _, schema := range s {
+		if err := schema.ValidateBytes(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonSchema requires valid JSON.
+type jsonSchema struct{}
+
+func (jsonSchema) ValidateBytes(data []byte) error {
+	_, err := parseJSON(data)
+	return err
+}
+
+// parseJSON decodes a JSON value, keeping numbers as json.Number. Syntax errors
+// are returned as an *editor.PositionError.
+func parseJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, editor.NewOffsetError(data, syntaxErr.Offset-1, err)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no JSON value")
+		}
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, editor.NewOffsetError(data, dec.InputOffset(), errors.New("unexpected data after JSON value"))
+	}
+	return v, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/confluentinc/go-editor/editortest"
+)
+
+func TestMain(m *testing.M) {
+	editortest.HelperMain()
+	os.Exit(m.Run())
+}
+
+func TestRun(t *testing.T) {
+	schemaFile := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(schemaFile, []byte(`{"required": ["name"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		input      string
+		script     string
+		wantOutput string
+		wantCode   int
+		wantStderr string
+		wantShown  int
+		unix       bool
+	}{
+		{
+			name:       "pass through edits",
+			input:      "original\n",
+			script:     `append "edited\n"`,
+			wantOutput: "original\nedited\n",
+			wantShown:  1,
+		},
+		{
+			name:       "unchanged",
+			input:      "original\n",
+			script:     "abort",
+			wantOutput: "original\n",
+			wantShown:  1,
+		},
+		{
+			name:       "strip comments",
+			args:       []string{"-ext", ".yaml", "-strip-comments"},
+			input:      "# explain\nkey: value\n",
+			script:     `append "other: value # new\n"`,
+			wantOutput: "key: value\nother: value\n",
+			wantShown:  1,
+		},
+		{
+			name:       "json retried",
+			args:       []string{"-json"},
+			input:      "{}\n",
+			script:     "replace \"{\\n\"\nreplace \"{\\\"a\\\": 1}\\n\"",
+			wantOutput: "{\"a\": 1}\n",
+			wantShown:  2,
+		},
+		{
+			name:       "json schema",
+			args:       []string{"-schema", schemaFile},
+			input:      "{}\n",
+			script:     "replace \"{\\\"other\\\": 1}\\n\"\nreplace \"{\\\"name\\\": 1}\\n\"",
+			wantOutput: "{\"name\": 1}\n",
+			wantShown:  2,
+		},
+		{
+			name:       "max attempts",
+			args:       []string{"-json", "-max-attempts", "1"},
+			input:      "{}\n",
+			script:     `replace "{\n"`,
+			wantCode:   1,
+			wantStderr: "go-editor:",
+			wantShown:  1,
+		},
+		{
+			name:       "validator",
+			args:       []string{"-validator", "grep -q valid"},
+			input:      "original\n",
+			script:     "replace \"other\\n\"\nreplace \"valid\\n\"",
+			wantOutput: "valid\n",
+			wantShown:  2,
+			unix:       true,
+		},
+		{
+			name:       "validator format",
+			args:       []string{"-validator", `sh -c 'grep -q "^valid" "$0" || { echo "1: not valid"; exit 1; }' {}`, "-validator-format", "%l: %m", "-max-attempts", "1"},
+			input:      "original\n",
+			script:     `replace "invalid\n"`,
+			wantCode:   1,
+			wantStderr: "line 1: not valid",
+			wantShown:  1,
+			unix:       true,
+		},
+		{
+			name:       "editor failed",
+			input:      "original\n",
+			script:     "exit 1",
+			wantCode:   1,
+			wantStderr: "exit status 1",
+			wantShown:  1,
+		},
+		{
+			name:       "bad flag",
+			args:       []string{"-unknown"},
+			wantCode:   2,
+			wantStderr: "usage: go-editor",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.unix && runtime.GOOS == "windows" {
+				t.Skip("needs grep")
+			}
+			t.Setenv("TMPDIR", t.TempDir()) // for preserved files
+			pe := editortest.NewProcessEditor(t, tt.script)
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			args := append([]string{"-editor", pe.Command}, tt.args...)
+
+			code := run(args, strings.NewReader(tt.input), stdout, stderr)
+			if code != tt.wantCode {
+				t.Errorf("run() = %d, want %d, stderr %q", code, tt.wantCode, stderr.String())
+			}
+			if stdout.String() != tt.wantOutput {
+				t.Errorf("run() output = %q, want %q", stdout.String(), tt.wantOutput)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("run() stderr = %q, want %q", stderr.String(), tt.wantStderr)
+			}
+			pe.AssertLaunched(t, tt.wantShown)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package editor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Codec converts Go values to and from an editable representation.
+//
+// Unmarshal must reject data it cannot map onto v, such as unknown fields, so
+// that mistakes are reported to the user instead of being silently dropped.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	// Extension is the file extension for the format, including the dot.
+	Extension() string
+	// CommentSyntax describes the comments allowed in the format.
+	CommentSyntax() CommentSyntax
+}
+
+var (
+	codecsMu    sync.RWMutex
+	codecs      = map[string]Codec{}
+	codecsByExt = map[string]Codec{}
+)
+
+func init() {
+	RegisterCodec("json", JSONCodec{})
+	RegisterCodec("env", EnvCodec{})
+	RegisterCodec("ini", INICodec{})
+	RegisterCodec("properties", PropertiesCodec{})
+}
+
+// RegisterCodec makes a codec available by name and by its extension,
+// replacing any codec previously registered for either.
+func RegisterCodec(name string, c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[strings.ToLower(name)] = c
+	if ext := c.Extension(); ext != "" {
+		codecsByExt[normalizeExt(ext)] = c
+	}
+}
+
+// LookupCodec returns the codec registered with the given name, like "json".
+func LookupCodec(name string) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	c, ok := codecs[strings.ToLower(name)]
+	return c, ok
+}
+
+// CodecForExtension returns the codec registered for the given file extension,
+// like ".json". The dot is optional.
+func CodecForExtension(ext string) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	c, ok := codecsByExt[normalizeExt(ext)]
+	return c, ok
+}
+
+func normalizeExt(ext string) string {
+	return "." + strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
+// PositionError is an error at a specific position of the edited data.
+// Line and Column are 1-based. Column is 0 if unknown.
+type PositionError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *PositionError) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// NewOffsetError returns a PositionError for the byte of data at the given
+// offset, like the Offset of a *json.SyntaxError.
+func NewOffsetError(data []byte, offset int64, err error) *PositionError {
+	offset = max(0, min(offset, int64(len(data))))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return &PositionError{Line: line, Column: col, Err: err}
+}
+
+// JSONCodec edits values as indented JSON. Comments and trailing commas are
+// allowed in the edited data (JSONC).
+type JSONCodec struct{}
+
+// Extension returns ".json".
+func (JSONCodec) Extension() string { return ".json" }
+
+// CommentSyntax returns CStyleComments.
+func (JSONCodec) CommentSyntax() CommentSyntax { return CStyleComments }
+
+// Marshal returns v as indented JSON with a trailing newline.
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Unmarshal decodes a single JSON value into v, rejecting unknown fields and
+// trailing data. Syntax and type errors are returned as a *PositionError.
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	data = StripJSONC(data)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			// the offsets count the bytes read, including the offending one
+			return NewOffsetError(data, syntaxErr.Offset-1, err)
+		case errors.As(err, &typeErr):
+			return NewOffsetError(data, typeErr.Offset-1, err)
+		case errors.Is(err, io.EOF):
+			return errors.New("no JSON value found")
+		}
+		return err
+	}
+	end := dec.InputOffset()
+	if _, err := dec.Token(); err != io.EOF {
+		end += int64(len(data[end:]) - len(bytes.TrimLeft(data[end:], " \t\r\n")))
+		return NewOffsetError(data, end, errors.New("unexpected data after JSON value"))
+	}
+	return nil
+}
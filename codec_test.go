@@ -0,0 +1,98 @@
+package editor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJSONCodec_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantErr  bool
+		wantLine int
+		wantCol  int
+	}{
+		{
+			name: "valid",
+			data: `{"name": "a"}`,
+		},
+		{
+			name:     "syntax error",
+			data:     "{\n  \"name\": a\n}",
+			wantErr:  true,
+			wantLine: 2,
+			wantCol:  11,
+		},
+		{
+			name:     "type error",
+			data:     "{\n  \"count\": \"a\"\n}",
+			wantErr:  true,
+			wantLine: 2,
+			wantCol:  14,
+		},
+		{
+			name:    "unknown field",
+			data:    `{"other": 1}`,
+			wantErr: true,
+		},
+		{
+			name:     "trailing data",
+			data:     "{}\n  {}",
+			wantErr:  true,
+			wantLine: 2,
+			wantCol:  3,
+		},
+		{
+			name:    "empty",
+			data:    "  ",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v editConfig
+			err := JSONCodec{}.Unmarshal([]byte(tt.data), &v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("JSONCodec.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var posErr *PositionError
+			if errors.As(err, &posErr) != (tt.wantLine != 0) {
+				t.Fatalf("JSONCodec.Unmarshal() error = %v, want position %v", err, tt.wantLine != 0)
+			}
+			if posErr != nil && (posErr.Line != tt.wantLine || posErr.Column != tt.wantCol) {
+				t.Errorf("JSONCodec.Unmarshal() position = %d:%d, want %d:%d", posErr.Line, posErr.Column, tt.wantLine, tt.wantCol)
+			}
+		})
+	}
+}
+
+func TestJSONCodec_UnmarshalJSONC(t *testing.T) {
+	var v editConfig
+	data := "{\n  // the name\n  \"name\": \"a\", /* inline */\n  \"count\": 1,\n}\n"
+	if err := (JSONCodec{}).Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("JSONCodec.Unmarshal() error = %v", err)
+	}
+	if want := (editConfig{Name: "a", Count: 1}); v != want {
+		t.Errorf("JSONCodec.Unmarshal() = %+v, want %+v", v, want)
+	}
+}
+
+func TestCodecRegistry(t *testing.T) {
+	for _, name := range []string{"json", "env", "ini", "properties"} {
+		c, ok := LookupCodec(name)
+		if !ok {
+			t.Fatalf("LookupCodec(%q) not found", name)
+		}
+		byExt, ok := CodecForExtension(c.Extension())
+		if !ok || byExt != c {
+			t.Errorf("CodecForExtension(%q) = %v, want %v", c.Extension(), byExt, c)
+		}
+	}
+	if c, ok := CodecForExtension("JSON"); !ok || c != (JSONCodec{}) {
+		t.Errorf("CodecForExtension(\"JSON\") = %v, want JSONCodec", c)
+	}
+	if _, ok := LookupCodec("yaml"); ok {
+		t.Errorf("LookupCodec(\"yaml\") found an unregistered codec")
+	}
+}
@@ -0,0 +1,197 @@
+package editor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommandSchema is a Schema which validates data with an external command, like
+// "promtool check config {}" or an in-house linter. The data is invalid if the
+// command exits with a non-zero status.
+type CommandSchema struct {
+	// Command is the command line, split into words like the editor command.
+	// "{}" is replaced by the path of a temporary file holding the data, also
+	// within an argument like "--config={}". Otherwise the data is passed on stdin.
+	Command string
+	// Extension of the temporary file, like ".yaml", for commands which need it.
+	Extension string
+	// Patterns parse the output of the command into positioned errors, in the
+	// style of Vim's errorformat: %f matches a file name, %l a line number, %c a
+	// column number, %m the message and %% a percent sign, like "%f:%l:%c: %m".
+	// Lines where %f isn't the temporary file or "-", like errors in included
+	// files, are skipped. If no line of the output matches, the whole output is
+	// the error.
+	Patterns []string
+	// Timeout stops the command if it runs longer, failing validation. Defaults to a minute.
+	Timeout time.Duration
+}
+
+// defaultCommandTimeout is the Timeout of a CommandSchema if it isn't set.
+const defaultCommandTimeout = time.Minute
+
+// ValidateBytes runs the command on data.
+func (s *CommandSchema) ValidateBytes(data []byte) error {
+	patterns, err := compilePatterns(s.Patterns)
+	if err != nil {
+		return err
+	}
+	args := splitCommand(s.Command)
+	if len(args) == 0 {
+		return errors.New("no validation command")
+	}
+
+	var (
+		stdin *bytes.Reader
+		file  string
+	)
+	if slices.ContainsFunc(args, func(arg string) bool { return strings.Contains(arg, "{}") }) {
+		f, err := os.CreateTemp("", "validate-*"+s.Extension)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		file = f.Name()
+		args = slices.Clone(args)
+		for i, arg := range args {
+			args[i] = strings.ReplaceAll(arg, "{}", file)
+		}
+	} else {
+		stdin = bytes.NewReader(data)
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	// don't wait for children of the command holding on to its output
+	cmd.WaitDelay = time.Second
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s: timed out after %v", args[0], timeout)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+
+	var errs []error
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimRight(line, "\r")
+		for _, p := range patterns {
+			if f, err := p.match(line); err != nil {
+				if f == "" || f == "-" || f == file {
+					errs = append(errs, err)
+				}
+				break
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if msg := strings.TrimSpace(string(out)); msg != "" {
+		return errors.New(msg)
+	}
+	return fmt.Errorf("%s: %w", args[0], err)
+}
+
+// outputPattern is a compiled errorformat pattern.
+type outputPattern struct {
+	re                      *regexp.Regexp
+	file, line, column, msg int
+}
+
+// compilePatterns translates errorformat patterns into regular expressions.
+func compilePatterns(patterns []string) ([]outputPattern, error) {
+	var compiled []outputPattern
+	for _, pattern := range patterns {
+		p := outputPattern{}
+		expr := &strings.Builder{}
+		expr.WriteString("^")
+		group := 0
+		for i := 0; i < len(pattern); i++ {
+			if pattern[i] != '%' {
+				expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+				continue
+			}
+			if i++; i == len(pattern) {
+				return nil, fmt.Errorf("pattern %q ends with %%", pattern)
+			}
+			switch pattern[i] {
+			case '%':
+				expr.WriteString("%")
+				continue
+			case 'f':
+				expr.WriteString(`(.+?)`)
+				p.file = group + 1
+			case 'l':
+				expr.WriteString(`(\d+)`)
+				p.line = group + 1
+			case 'c':
+				expr.WriteString(`(\d+)`)
+				p.column = group + 1
+			case 'm':
+				expr.WriteString(`(.*)`)
+				p.msg = group + 1
+			default:
+				return nil, fmt.Errorf("pattern %q has unknown %%%c", pattern, pattern[i])
+			}
+			group++
+		}
+		expr.WriteString("$")
+		if p.msg == 0 {
+			return nil, fmt.Errorf("pattern %q has no %%m", pattern)
+		}
+		re, err := regexp.Compile(expr.String())
+		if err != nil {
+			return nil, err
+		}
+		p.re = re
+		compiled = append(compiled, p)
+	}
+	return compiled, nil
+}
+
+// match returns the error described by a line of output, and its file if the
+// pattern has one, or a nil error if the line doesn't match.
+func (p outputPattern) match(line string) (file string, err error) {
+	m := p.re.FindStringSubmatch(line)
+	if m == nil {
+		return "", nil
+	}
+	if p.file > 0 {
+		file = m[p.file]
+	}
+	msg := errors.New(strings.TrimSpace(m[p.msg]))
+	if p.line == 0 {
+		return file, msg
+	}
+	pe := &PositionError{Err: msg}
+	pe.Line, _ = strconv.Atoi(m[p.line])
+	if p.column > 0 {
+		pe.Column, _ = strconv.Atoi(m[p.column])
+	}
+	return file, pe
+}
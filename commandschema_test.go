@@ -0,0 +1,148 @@
+package editor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHelperValidator isn't a real test. It's a validation command run by
+// TestCommandSchema, which reads the data from the file given as the last
+// argument, possibly after "=", or stdin, and reports each line containing
+// "invalid", and an error in another file for each line containing "include".
+func TestHelperValidator(t *testing.T) {
+	if os.Getenv("GO_EDITOR_HELPER_VALIDATOR") != "1" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	var data []byte
+	name := "-"
+	if len(args) > 1 {
+		_, name, _ = strings.Cut(args[len(args)-1], "=")
+		if name == "" {
+			name = args[len(args)-1]
+		}
+		data, _ = os.ReadFile(name)
+	} else {
+		data, _ = io.ReadAll(os.Stdin)
+	}
+	if strings.Contains(string(data), "hang") {
+		time.Sleep(time.Minute)
+	}
+	code := 0
+	for i, line := range strings.Split(string(data), "\n") {
+		if col := strings.Index(line, "invalid"); col >= 0 {
+			fmt.Printf("%s:%d:%d: found %q\n", name, i+1, col+1, strings.TrimSpace(line))
+			code = 1
+		}
+		if strings.Contains(line, "include") {
+			fmt.Printf("other.txt:%d:1: bad include\n", i+7)
+			code = 1
+		}
+	}
+	if strings.Contains(string(data), "fail") {
+		fmt.Println("unexpected failure")
+		code = 2
+	}
+	os.Exit(code)
+}
+
+func TestCommandSchema(t *testing.T) {
+	t.Setenv("GO_EDITOR_HELPER_VALIDATOR", "1")
+	helper := strconv.Quote(os.Args[0]) + " -test.run=^TestHelperValidator$ --"
+
+	tests := []struct {
+		name     string
+		schema   CommandSchema
+		data     string
+		wantErr  string
+		wantDiag []Diagnostic
+	}{
+		{name: "valid on stdin", schema: CommandSchema{Command: helper}, data: "ok\n"},
+		{name: "valid in file", schema: CommandSchema{Command: helper + " {}", Extension: ".txt"}, data: "ok\n"},
+		{
+			name:     "invalid without patterns",
+			schema:   CommandSchema{Command: helper},
+			data:     "ok\ninvalid\n",
+			wantErr:  `-:2:1: found "invalid"`,
+			wantDiag: []Diagnostic{{Message: `-:2:1: found "invalid"`}},
+		},
+		{
+			name:    "invalid with patterns",
+			schema:  CommandSchema{Command: helper + " {}", Patterns: []string{"%f:%l:%c: %m"}},
+			data:    "ok\n  invalid\ninvalid too\n",
+			wantErr: "line 2, column 3: found \"invalid\"\nline 3, column 1: found \"invalid too\"",
+			wantDiag: []Diagnostic{
+				{Line: 2, Column: 3, Message: `found "invalid"`},
+				{Line: 3, Column: 1, Message: `found "invalid too"`},
+			},
+		},
+		{
+			name:     "file within argument",
+			schema:   CommandSchema{Command: helper + " --config={}", Patterns: []string{"%f:%l:%c: %m"}},
+			data:     "invalid\n",
+			wantErr:  `line 1, column 1: found "invalid"`,
+			wantDiag: []Diagnostic{{Line: 1, Column: 1, Message: `found "invalid"`}},
+		},
+		{
+			name:     "other files skipped",
+			schema:   CommandSchema{Command: helper + " {}", Patterns: []string{"%f:%l:%c: %m"}},
+			data:     "include\ninvalid\n",
+			wantErr:  `line 2, column 1: found "invalid"`,
+			wantDiag: []Diagnostic{{Line: 2, Column: 1, Message: `found "invalid"`}},
+		},
+		{
+			name:     "timeout",
+			schema:   CommandSchema{Command: helper, Timeout: 100 * time.Millisecond},
+			data:     "hang\n",
+			wantErr:  os.Args[0] + ": timed out after 100ms",
+			wantDiag: []Diagnostic{{Message: os.Args[0] + ": timed out after 100ms"}},
+		},
+		{
+			name:     "unknown pattern verb",
+			schema:   CommandSchema{Command: helper, Patterns: []string{"%f:%l:%*: %m"}},
+			data:     "invalid\n",
+			wantErr:  `pattern "%f:%l:%*: %m" has unknown %*`,
+			wantDiag: []Diagnostic{{Message: `pattern "%f:%l:%*: %m" has unknown %*`}},
+		},
+		{
+			name:     "unmatched output",
+			schema:   CommandSchema{Command: helper, Patterns: []string{"%f:%l:%c: %m"}},
+			data:     "fail\n",
+			wantErr:  "unexpected failure",
+			wantDiag: []Diagnostic{{Message: "unexpected failure"}},
+		},
+		{
+			name:     "line only",
+			schema:   CommandSchema{Command: helper, Patterns: []string{"-:%l:1: %m"}},
+			data:     "invalid\n",
+			wantErr:  `line 1: found "invalid"`,
+			wantDiag: []Diagnostic{{Line: 1, Message: `found "invalid"`}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schema.ValidateBytes([]byte(tt.data))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateBytes() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ValidateBytes() error = %v, want %q", err, tt.wantErr)
+			}
+			if got := Diagnostics(err); !reflect.DeepEqual(got, tt.wantDiag) {
+				t.Errorf("Diagnostics() = %v, want %v", got, tt.wantDiag)
+			}
+		})
+	}
+}
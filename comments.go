@@ -0,0 +1,263 @@
+package editor
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+)
+
+// Delimiters mark the start and end of a block comment or a string.
+type Delimiters struct {
+	Start string
+	End   string
+}
+
+// CommentSyntax describes the comments of a file format, for determining
+// "empty" files and removing comments.
+type CommentSyntax struct {
+	// Line comments start with one of these prefixes and end at the newline.
+	Line []string
+	// LineStart only recognizes line comments at the start of a line, ignoring whitespace.
+	LineStart bool
+	// AfterSpace only recognizes line comments at the start of a line or after whitespace,
+	// like "#" in YAML or shell scripts, where "a#b" is a value.
+	AfterSpace bool
+	// Block comments, like "/*" and "*/".
+	Block []Delimiters
+	// Nested allows block comments to contain other block comments.
+	Nested bool
+	// Strings may contain comment markers. They end at the newline if unterminated.
+	Strings []Delimiters
+	// MultilineStrings may contain comment markers and newlines.
+	MultilineStrings []Delimiters
+	// Docstrings are multi-line strings which count as comments for IsEmpty, but
+	// are kept by Strip, like Python's triple-quoted strings.
+	Docstrings []Delimiters
+}
+
+var (
+	// HashComments are "#" line comments at the start of a line or after
+	// whitespace, like in YAML or shell scripts.
+	HashComments = CommentSyntax{
+		Line:       []string{"#"},
+		AfterSpace: true,
+		Strings:    []Delimiters{{`"`, `"`}},
+	}
+	// CStyleComments are "//" line and "/* */" block comments, like in JSONC or Go.
+	CStyleComments = CommentSyntax{
+		Line:             []string{"//"},
+		Block:            []Delimiters{{"/*", "*/"}},
+		Strings:          []Delimiters{{`"`, `"`}},
+		MultilineStrings: []Delimiters{{"`", "`"}},
+	}
+	// XMLComments are "<!-- -->" block comments, like in XML or HTML.
+	XMLComments = CommentSyntax{
+		Block: []Delimiters{{"<!--", "-->"}},
+	}
+	// PythonComments are "#" line comments and docstrings.
+	PythonComments = CommentSyntax{
+		Line:       []string{"#"},
+		Strings:    []Delimiters{{`"`, `"`}, {"'", "'"}},
+		Docstrings: []Delimiters{{`"""`, `"""`}, {"'''", "'''"}},
+	}
+	// TOMLComments are "#" line comments, ignoring multi-line strings.
+	TOMLComments = CommentSyntax{
+		Line:             []string{"#"},
+		Strings:          []Delimiters{{`"`, `"`}, {"'", "'"}},
+		MultilineStrings: []Delimiters{{`"""`, `"""`}, {"'''", "'''"}},
+	}
+	// SQLComments are "--" line and "/* */" block comments.
+	SQLComments = CommentSyntax{
+		Line:    []string{"--"},
+		Block:   []Delimiters{{"/*", "*/"}},
+		Strings: []Delimiters{{"'", "'"}},
+	}
+	// HaskellComments are "--" line and nested "{- -}" block comments.
+	HaskellComments = CommentSyntax{
+		Line:    []string{"--"},
+		Block:   []Delimiters{{"{-", "-}"}},
+		Nested:  true,
+		Strings: []Delimiters{{`"`, `"`}},
+	}
+	// INIComments are ";" and "#" comment lines.
+	INIComments = CommentSyntax{
+		Line:      []string{";", "#"},
+		LineStart: true,
+	}
+	// PropertiesComments are "#" and "!" comment lines.
+	PropertiesComments = CommentSyntax{
+		Line:      []string{"#", "!"},
+		LineStart: true,
+	}
+
+	commentSyntaxes = map[string]CommentSyntax{
+		"yaml": HashComments, "yml": HashComments, "sh": HashComments, "env": HashComments,
+		"json": CStyleComments, "jsonc": CStyleComments, "js": CStyleComments, "go": CStyleComments,
+		"xml": XMLComments, "html": XMLComments, "svg": XMLComments,
+		"py":   PythonComments,
+		"toml": TOMLComments,
+		"sql":  SQLComments,
+		"hs":   HaskellComments,
+		"ini":  INIComments, "cfg": INIComments,
+		"properties": PropertiesComments,
+	}
+)
+
+// CommentSyntaxFor returns the comment syntax of a format given by name or file
+// extension, like "yaml" or ".yml".
+func CommentSyntaxFor(format string) (CommentSyntax, bool) {
+	s, ok := commentSyntaxes[strings.ToLower(strings.TrimPrefix(format, "."))]
+	return s, ok
+}
+
+// IsEmpty returns true if data only holds whitespace, comments and docstrings.
+func (s CommentSyntax) IsEmpty(data []byte) bool {
+	s.Block = append(slices.Clip(s.Block), s.Docstrings...)
+	s.Docstrings = nil
+	return len(bytes.TrimSpace(s.blank(data))) == 0
+}
+
+// Strip removes comments from data, keeping docstrings. Lines which only held
+// comments are removed and whitespace left at the end of lines is trimmed.
+func (s CommentSyntax) Strip(data []byte) []byte {
+	return dropBlankedLines(data, s.blank(data))
+}
+
+// blank returns a copy of data with every comment byte other than a newline
+// replaced by a space.
+func (s CommentSyntax) blank(data []byte) []byte {
+	out := make([]byte, len(data))
+	copy(out, data)
+	lineStart := true
+	for i := 0; i < len(data); {
+		switch data[i] {
+		case '\n':
+			lineStart = true
+			fallthrough
+		case ' ', '\t', '\r':
+			i++
+			continue
+		}
+		if end, ok := s.blockComment(data, i); ok {
+			blankRange(out, i, end)
+			i = end
+			continue
+		}
+		afterSpace := i == 0 || strings.IndexByte(" \t\r\n", data[i-1]) >= 0
+		if (lineStart || !s.LineStart) && (afterSpace || !s.AfterSpace) {
+			if p, ok := hasPrefixAt(data, i, s.Line); ok {
+				end := i + len(p)
+				for end < len(data) && data[end] != '\n' && data[end] != '\r' {
+					end++
+				}
+				blankRange(out, i, end)
+				i = end
+				continue
+			}
+		}
+		lineStart = false
+		if end, ok := skipString(data, i, s.Docstrings, true); ok {
+			i = end
+			continue
+		}
+		if end, ok := skipString(data, i, s.MultilineStrings, true); ok {
+			i = end
+			continue
+		}
+		if end, ok := skipString(data, i, s.Strings, false); ok {
+			i = end
+			continue
+		}
+		i++
+	}
+	return out
+}
+
+// blockComment returns the end of a block comment starting at i.
+func (s CommentSyntax) blockComment(data []byte, i int) (int, bool) {
+	var open Delimiters
+	found := false
+	for _, b := range s.Block {
+		if b.Start != "" && bytes.HasPrefix(data[i:], []byte(b.Start)) {
+			open, found = b, true
+			break
+		}
+	}
+	if !found {
+		return 0, false
+	}
+	depth := 1
+	for i += len(open.Start); i < len(data); i++ {
+		switch {
+		case bytes.HasPrefix(data[i:], []byte(open.End)):
+			depth--
+			i += len(open.End) - 1
+			if depth == 0 || !s.Nested {
+				return i + 1, true
+			}
+		case s.Nested && bytes.HasPrefix(data[i:], []byte(open.Start)):
+			depth++
+			i += len(open.Start) - 1
+		}
+	}
+	return len(data), true
+}
+
+// skipString returns the end of a string starting at i. Backslashes escape the next byte.
+func skipString(data []byte, i int, delims []Delimiters, multiline bool) (int, bool) {
+	for _, d := range delims {
+		if d.Start == "" || !bytes.HasPrefix(data[i:], []byte(d.Start)) {
+			continue
+		}
+		for j := i + len(d.Start); j < len(data); j++ {
+			switch {
+			case data[j] == '\\':
+				j++
+			case data[j] == '\n' && !multiline:
+				return j, true
+			case bytes.HasPrefix(data[j:], []byte(d.End)):
+				return j + len(d.End), true
+			}
+		}
+		return len(data), true
+	}
+	return 0, false
+}
+
+func hasPrefixAt(data []byte, i int, prefixes []string) (string, bool) {
+	for _, p := range prefixes {
+		if p != "" && bytes.HasPrefix(data[i:], []byte(p)) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+func blankRange(data []byte, start, end int) {
+	for i := start; i < end; i++ {
+		if data[i] != '\n' && data[i] != '\r' {
+			data[i] = ' '
+		}
+	}
+}
+
+// dropBlankedLines compares the lines of data with those of a blanked copy. Lines
+// which were blanked entirely are dropped, and trailing whitespace is trimmed from
+// lines which were partially blanked. Other lines are kept as is.
+func dropBlankedLines(data, blanked []byte) []byte {
+	out := &bytes.Buffer{}
+	origLines := bytes.SplitAfter(data, []byte("\n"))
+	for i, line := range bytes.SplitAfter(blanked, []byte("\n")) {
+		if bytes.Equal(line, origLines[i]) {
+			out.Write(line)
+			continue
+		}
+		trimmed := bytes.TrimRight(line, " \t\r\n")
+		if len(bytes.TrimSpace(trimmed)) == 0 {
+			continue
+		}
+		out.Write(trimmed)
+		out.Write(line[len(bytes.TrimRight(line, "\r\n")):])
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,142 @@
+package editor
+
+import "testing"
+
+func TestCommentSyntax_IsEmpty(t *testing.T) {
+	tests := []struct {
+		name   string
+		syntax CommentSyntax
+		data   string
+		want   bool
+	}{
+		{
+			name:   "c block comment",
+			syntax: CStyleComments,
+			data:   "/* nothing\n   here */\n// at all\n",
+			want:   true,
+		},
+		{
+			name:   "c block comment then content",
+			syntax: CStyleComments,
+			data:   "/* nothing */ {}",
+			want:   false,
+		},
+		{
+			name:   "xml comment",
+			syntax: XMLComments,
+			data:   "<!--\n  <root/>\n-->\n",
+			want:   true,
+		},
+		{
+			name:   "xml content",
+			syntax: XMLComments,
+			data:   "<!-- a --><root/>",
+			want:   false,
+		},
+		{
+			name:   "python docstring",
+			syntax: PythonComments,
+			data:   "\"\"\"\ndoc\n\"\"\"\n# comment\n",
+			want:   true,
+		},
+		{
+			name:   "toml multiline string with hash line",
+			syntax: TOMLComments,
+			data:   "\"\"\"\n# not a comment\n\"\"\"\n",
+			want:   false,
+		},
+		{
+			name:   "hash inside string",
+			syntax: HashComments,
+			data:   "\"# not a comment\"",
+			want:   false,
+		},
+		{
+			name:   "nested block comments",
+			syntax: HaskellComments,
+			data:   "{- outer {- inner -} still outer -}\n-- line",
+			want:   true,
+		},
+		{
+			name:   "unnested block comments end early",
+			syntax: CommentSyntax{Block: []Delimiters{{"{-", "-}"}}},
+			data:   "{- outer {- inner -} not a comment -}",
+			want:   false,
+		},
+		{
+			name:   "line start only",
+			syntax: INIComments,
+			data:   "  ; comment\n# comment",
+			want:   true,
+		},
+		{
+			name:   "unterminated block comment",
+			syntax: CStyleComments,
+			data:   "/* never closed",
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.syntax.IsEmpty([]byte(tt.data)); got != tt.want {
+				t.Errorf("CommentSyntax.IsEmpty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommentSyntax_Strip(t *testing.T) {
+	tests := []struct {
+		name   string
+		syntax CommentSyntax
+		data   string
+		want   string
+	}{
+		{
+			name:   "hash comments",
+			syntax: HashComments,
+			data:   "# heading\nkey: value # note\nurl: \"a#b\"\n",
+			want:   "key: value\nurl: \"a#b\"\n",
+		},
+		{
+			name:   "hash in values",
+			syntax: HashComments,
+			data:   "color: a#b\nPASSWORD=abc#123\ncolor: #fff #white\n\t# indented\n",
+			want:   "color: a#b\nPASSWORD=abc#123\ncolor:\n",
+		},
+		{
+			name:   "block comments",
+			syntax: CStyleComments,
+			data:   "a /* one\ntwo */ b\n/* gone */\nc\n",
+			want:   "a\n       b\nc\n",
+		},
+		{
+			name:   "line start only",
+			syntax: INIComments,
+			data:   "; comment\nkey = a ; b\n",
+			want:   "key = a ; b\n",
+		},
+		{
+			name:   "python docstrings kept",
+			syntax: PythonComments,
+			data:   "# comment\nx = \"\"\"\n# not a comment\n\"\"\"  # note\n",
+			want:   "x = \"\"\"\n# not a comment\n\"\"\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tt.syntax.Strip([]byte(tt.data))); got != tt.want {
+				t.Errorf("CommentSyntax.Strip() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommentSyntaxFor(t *testing.T) {
+	if s, ok := CommentSyntaxFor(".YML"); !ok || s.Line[0] != "#" {
+		t.Errorf("CommentSyntaxFor(\".YML\") = %v, %v", s, ok)
+	}
+	if _, ok := CommentSyntaxFor("unknown"); ok {
+		t.Errorf("CommentSyntaxFor(\"unknown\") found a syntax")
+	}
+}
@@ -0,0 +1,93 @@
+package editor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+// IgnoreTrailingWhitespace compares data ignoring line endings (CRLF or LF),
+// whitespace at the end of lines and blank lines at the end of the data.
+func IgnoreTrailingWhitespace(a, b []byte) bool {
+	return bytes.Equal(trimTrailingWhitespace(a), trimTrailingWhitespace(b))
+}
+
+func trimTrailingWhitespace(data []byte) []byte {
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		lines[i] = bytes.TrimRight(line, " \t\r")
+	}
+	return bytes.TrimRight(bytes.Join(lines, []byte("\n")), "\n")
+}
+
+// IgnoreComments returns a Comparator which compares data without comments of
+// the given syntax, also ignoring trailing whitespace.
+func IgnoreComments(syntax CommentSyntax) Comparator {
+	return func(a, b []byte) bool {
+		return IgnoreTrailingWhitespace(syntax.Strip(a), syntax.Strip(b))
+	}
+}
+
+// JSONEqual compares data as JSON values, ignoring formatting, comments, trailing
+// commas and the order of object keys. Numbers are compared by value. If either
+// is not valid JSON, the data is compared with IgnoreTrailingWhitespace.
+func JSONEqual(a, b []byte) bool {
+	var va, vb any
+	if decodeJSONValue(a, &va) != nil || decodeJSONValue(b, &vb) != nil {
+		return IgnoreTrailingWhitespace(a, b)
+	}
+	return JSONValuesEqual(va, vb)
+}
+
+func decodeJSONValue(data []byte, v *any) error {
+	dec := json.NewDecoder(bytes.NewReader(StripJSONC(data)))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// JSONValuesEqual reports whether decoded JSON values are equal. Numbers are
+// compared by value if they were decoded as json.Number.
+func JSONValuesEqual(a, b any) bool {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, va := range a {
+			vb, ok := b[k]
+			if !ok || !JSONValuesEqual(va, vb) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !JSONValuesEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		ra, okA := new(big.Rat).SetString(a.String())
+		rb, okB := new(big.Rat).SetString(b.String())
+		return okA && okB && ra.Cmp(rb) == 0
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,97 @@
+package editor
+
+import "testing"
+
+func TestComparators(t *testing.T) {
+	tests := []struct {
+		name  string
+		equal Comparator
+		a, b  string
+		want  bool
+	}{
+		{
+			name:  "trailing newline added",
+			equal: IgnoreTrailingWhitespace,
+			a:     "a: 1",
+			b:     "a: 1\n",
+			want:  true,
+		},
+		{
+			name:  "crlf conversion",
+			equal: IgnoreTrailingWhitespace,
+			a:     "a: 1\nb: 2\n",
+			b:     "a: 1\r\nb: 2\r\n",
+			want:  true,
+		},
+		{
+			name:  "trailing spaces",
+			equal: IgnoreTrailingWhitespace,
+			a:     "a: 1  \nb: 2\n",
+			b:     "a: 1\nb: 2\t\n\n",
+			want:  true,
+		},
+		{
+			name:  "leading whitespace matters",
+			equal: IgnoreTrailingWhitespace,
+			a:     "a: 1\n",
+			b:     "  a: 1\n",
+			want:  false,
+		},
+		{
+			name:  "comments ignored",
+			equal: IgnoreComments(HashComments),
+			a:     "# please edit\na: 1\n",
+			b:     "a: 1 # note\n",
+			want:  true,
+		},
+		{
+			name:  "comments ignored but not content",
+			equal: IgnoreComments(HashComments),
+			a:     "a: 1\n",
+			b:     "a: 2\n",
+			want:  false,
+		},
+		{
+			name:  "hash in values",
+			equal: IgnoreComments(HashComments),
+			a:     "COLOR=#fff\n",
+			b:     "COLOR=#000\n",
+			want:  false,
+		},
+		{
+			name:  "json reindented and reordered",
+			equal: JSONEqual,
+			a:     `{"a": 1, "b": [true, null, "x"]}`,
+			b:     "{\n  \"b\": [true, null, \"x\"],\n  // comment\n  \"a\": 1.0,\n}\n",
+			want:  true,
+		},
+		{
+			name:  "json values differ",
+			equal: JSONEqual,
+			a:     `{"a": 1, "b": [1, 2]}`,
+			b:     `{"a": 1, "b": [2, 1]}`,
+			want:  false,
+		},
+		{
+			name:  "json big numbers",
+			equal: JSONEqual,
+			a:     `12345678901234567890`,
+			b:     `12345678901234567891`,
+			want:  false,
+		},
+		{
+			name:  "invalid json falls back",
+			equal: JSONEqual,
+			a:     "{\n",
+			b:     "{\r\n",
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.equal([]byte(tt.a), []byte(tt.b)); got != tt.want {
+				t.Errorf("Comparator(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
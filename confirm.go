@@ -0,0 +1,53 @@
+package editor
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ConfirmAction is the decision made when reviewing valid edits.
+type ConfirmAction int
+
+const (
+	// ConfirmApply accepts the edits.
+	ConfirmApply ConfirmAction = iota
+	// ConfirmEditAgain reopens the editor with the edits.
+	ConfirmEditAgain
+	// ConfirmDiscard cancels editing.
+	ConfirmDiscard
+)
+
+var (
+	msgConfirmPrompt  = "Apply these changes?"
+	msgConfirmOptions = []string{"apply", "edit again", "discard"}
+)
+
+// NewDiffConfirm returns a ConfirmFn which shows a unified diff of the edits and
+// asks whether to apply them, edit again or discard them, in the language of the
+// catalog. The edits are discarded if the reporter runs out of input or cannot prompt.
+func NewDiffConfirm(r Reporter, messages Catalog, color bool) ConfirmFn {
+	options := []string{
+		messages.Get(MsgConfirmApply),
+		messages.Get(MsgConfirmEditAgain),
+		messages.Get(MsgConfirmDiscard),
+	}
+	return func(original, edited []byte) (ConfirmAction, error) {
+		buf := &bytes.Buffer{}
+		opts := DiffOptions{OriginalName: "original", EditedName: "edited", Color: color}
+		if err := WriteDiff(buf, Diff(original, edited), opts); err != nil {
+			return ConfirmDiscard, err
+		}
+		r.Info(strings.TrimSuffix(buf.String(), "\n"))
+
+		choice, err := r.Prompt(messages.Get(MsgConfirmPrompt), options)
+		if errors.Is(err, io.EOF) || errors.Is(err, ErrNoPrompt) {
+			return ConfirmDiscard, nil
+		}
+		if err != nil {
+			return ConfirmDiscard, err
+		}
+		return ConfirmAction(choice), nil
+	}
+}
@@ -0,0 +1,111 @@
+package editor
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewDiffConfirm(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  ConfirmAction
+	}{
+		{name: "apply", input: "a\n", want: ConfirmApply},
+		{name: "edit again", input: "Edit Again\n", want: ConfirmEditAgain},
+		{name: "discard", input: "d\n", want: ConfirmDiscard},
+		{name: "retry on unknown answer", input: "what\n1\n", want: ConfirmApply},
+		{name: "answer without newline", input: "a", want: ConfirmApply},
+		{name: "discard at end of input", input: "", want: ConfirmDiscard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			confirm := NewDiffConfirm(NewTextReporter(strings.NewReader(tt.input), out, out), nil, false)
+			got, err := confirm([]byte("a\n"), []byte("b\n"))
+			if err != nil {
+				t.Fatalf("NewDiffConfirm() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NewDiffConfirm() = %v, want %v", got, tt.want)
+			}
+			if !strings.Contains(out.String(), "-a\n+b\n") {
+				t.Errorf("NewDiffConfirm() output = %q, want a diff", out.String())
+			}
+		})
+	}
+}
+
+func TestNewDiffConfirm_NopReporter(t *testing.T) {
+	got, err := NewDiffConfirm(NopReporter{}, nil, false)([]byte("a\n"), []byte("b\n"))
+	if err != nil || got != ConfirmDiscard {
+		t.Errorf("NewDiffConfirm() = %v, %v, want %v", got, err, ConfirmDiscard)
+	}
+}
+
+func TestValidatingEditor_Confirm(t *testing.T) {
+	tests := []struct {
+		name     string
+		actions  []ConfirmAction
+		edited   []string
+		wantData string
+		wantErr  string
+	}{
+		{
+			name:     "apply",
+			actions:  []ConfirmAction{ConfirmApply},
+			edited:   []string{"new data\n"},
+			wantData: "new data\n",
+		},
+		{
+			name:    "discard",
+			actions: []ConfirmAction{ConfirmDiscard},
+			edited:  []string{"new data\n"},
+			wantErr: msgCancelledDiscarded,
+		},
+		{
+			name:     "edit again",
+			actions:  []ConfirmAction{ConfirmEditAgain, ConfirmApply},
+			edited:   []string{"new data\n", "newer data\n"},
+			wantData: "newer data\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewValidatingEditor(&alwaysValidSchema{})
+			var shown []string
+			e.LaunchFn = func(command, file string) error {
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return err
+				}
+				shown = append(shown, string(data))
+				return os.WriteFile(file, []byte(tt.edited[len(shown)-1]), 0600)
+			}
+			confirms := 0
+			e.ConfirmFn = func(original, edited []byte) (ConfirmAction, error) {
+				if string(original) != "original\n" {
+					t.Errorf("ConfirmFn() original = %q", original)
+				}
+				confirms++
+				return tt.actions[confirms-1], nil
+			}
+			data, file, err := e.LaunchTempFile("prefix", bytes.NewBufferString("original\n"))
+			defer os.Remove(file)
+			if (err != nil) != (tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("ValidatingEditor.LaunchTempFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(data) != tt.wantData {
+				t.Errorf("ValidatingEditor.LaunchTempFile() data = %q, want %q", data, tt.wantData)
+			}
+			if confirms != len(tt.actions) {
+				t.Errorf("ValidatingEditor.LaunchTempFile() confirms = %d, want %d", confirms, len(tt.actions))
+			}
+			if len(shown) > 1 && shown[1] != tt.edited[0] {
+				t.Errorf("ValidatingEditor.LaunchTempFile() reopened with %q, want %q", shown[1], tt.edited[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package editor
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// DiffOp is the kind of change of a line in a diff.
+type DiffOp int
+
+const (
+	// DiffEqual is a line present in both the original and edited data.
+	DiffEqual DiffOp = iota
+	// DiffDelete is a line only present in the original data.
+	DiffDelete
+	// DiffInsert is a line only present in the edited data.
+	DiffInsert
+)
+
+// DiffContextLines is the number of unchanged lines around changes in a Hunk.
+const DiffContextLines = 3
+
+// DiffLine is a line in a diff. Text includes the line's newline, if any.
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// Hunk is a group of changed lines with surrounding context. Starts are 1-based
+// line numbers, as in a unified diff.
+type Hunk struct {
+	OriginalStart int
+	OriginalLines int
+	EditedStart   int
+	EditedLines   int
+	Lines         []DiffLine
+}
+
+// DiffOptions controls how a diff is written.
+type DiffOptions struct {
+	// OriginalName and EditedName are used in the "---" and "+++" header lines, which are omitted if both are empty.
+	OriginalName string
+	EditedName   string
+	// Color highlights the diff with ANSI escape codes.
+	Color bool
+}
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// Diff compares the lines of original and edited data, returning the hunks of a
+// unified diff. It returns nil if the data is equal.
+func Diff(original, edited []byte) []Hunk {
+	return hunks(diffLines(splitLines(original), splitLines(edited)))
+}
+
+// WriteDiff writes hunks as a unified diff.
+func WriteDiff(w io.Writer, hunks []Hunk, opts DiffOptions) error {
+	color := func(code, s string) string {
+		if !opts.Color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	buf := &bytes.Buffer{}
+	if opts.OriginalName != "" || opts.EditedName != "" {
+		buf.WriteString(color(ansiBold, "--- "+opts.OriginalName) + "\n")
+		buf.WriteString(color(ansiBold, "+++ "+opts.EditedName) + "\n")
+	}
+	for _, h := range hunks {
+		header := fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OriginalStart, h.OriginalLines), hunkRange(h.EditedStart, h.EditedLines))
+		buf.WriteString(color(ansiCyan, header) + "\n")
+		for _, l := range h.Lines {
+			text := strings.TrimSuffix(l.Text, "\n")
+			switch l.Op {
+			case DiffEqual:
+				buf.WriteString(" " + text + "\n")
+			case DiffDelete:
+				buf.WriteString(color(ansiRed, "-"+text) + "\n")
+			case DiffInsert:
+				buf.WriteString(color(ansiGreen, "+"+text) + "\n")
+			}
+			if !strings.HasSuffix(l.Text, "\n") {
+				buf.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprint(start)
+	}
+	if lines == 0 {
+		// an empty range refers to the line before it
+		start--
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// splitLines splits data after each newline.
+func splitLines(data []byte) []string {
+	var lines []string
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n') + 1
+		if i == 0 {
+			i = len(data)
+		}
+		lines = append(lines, string(data[:i]))
+		data = data[i:]
+	}
+	return lines
+}
+
+// diffLines returns a shortest edit script from a to b, using the linear space
+// variant of Myers' algorithm. Within each run of changes, deletions come first.
+func diffLines(a, b []string) []DiffLine {
+	// compare numbers instead of strings
+	ids := map[string]int{}
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, l := range lines {
+			id, ok := ids[l]
+			if !ok {
+				id = len(ids)
+				ids[l] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	d := &differ{a: a, b: b, x: intern(a), y: intern(b)}
+	d.diff(0, len(a), 0, len(b))
+	return deletionsFirst(d.script)
+}
+
+// differ holds the lines being compared and the edit script so far.
+type differ struct {
+	a, b   []string
+	x, y   []int
+	script []DiffLine
+}
+
+// diff appends the edit script from a[aLo:aHi] to b[bLo:bHi], splitting it at
+// a middle snake until only insertions or deletions are left.
+func (d *differ) diff(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.x[aLo] == d.y[bLo] {
+		d.script = append(d.script, DiffLine{Op: DiffEqual, Text: d.a[aLo]})
+		aLo, bLo = aLo+1, bLo+1
+	}
+	suffix := aHi
+	for aHi > aLo && bHi > bLo && d.x[aHi-1] == d.y[bHi-1] {
+		aHi, bHi = aHi-1, bHi-1
+	}
+
+	switch {
+	case aLo == aHi:
+		for _, l := range d.b[bLo:bHi] {
+			d.script = append(d.script, DiffLine{Op: DiffInsert, Text: l})
+		}
+	case bLo == bHi:
+		for _, l := range d.a[aLo:aHi] {
+			d.script = append(d.script, DiffLine{Op: DiffDelete, Text: l})
+		}
+	default:
+		// with a common prefix and suffix removed, at least two edits are
+		// needed, so both sides of the snake need fewer
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.diff(aLo, x, bLo, y)
+		for _, l := range d.a[x:u] {
+			d.script = append(d.script, DiffLine{Op: DiffEqual, Text: l})
+		}
+		d.diff(u, aHi, v, bHi)
+	}
+
+	for _, l := range d.a[aHi:suffix] {
+		d.script = append(d.script, DiffLine{Op: DiffEqual, Text: l})
+	}
+}
+
+// middleSnake finds the snake in the middle of a shortest edit script from
+// a[aLo:aHi] to b[bLo:bHi], by searching forwards from the start and backwards
+// from the end until the paths overlap. It returns the start and end of the
+// snake, which may be empty.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	// the furthest x on each diagonal k = x - y, forwards, and backwards with
+	// x and y counted from the end
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+
+	for D := 0; D <= maxD; D++ {
+		for k := -D; k <= D; k += 2 {
+			x := forward[offset+k+1]
+			if k != -D && (k == D || forward[offset+k-1] >= forward[offset+k+1]) {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.x[aLo+x] == d.y[bLo+y] {
+				x, y = x+1, y+1
+			}
+			forward[offset+k] = x
+			if r := delta - k; odd && r >= -(D-1) && r <= D-1 && x+backward[offset+r] >= n {
+				return aLo + startX, bLo + startY, aLo + x, bLo + y
+			}
+		}
+		for k := -D; k <= D; k += 2 {
+			x := backward[offset+k+1]
+			if k != -D && (k == D || backward[offset+k-1] >= backward[offset+k+1]) {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.x[aHi-1-x] == d.y[bHi-1-y] {
+				x, y = x+1, y+1
+			}
+			backward[offset+k] = x
+			if r := delta - k; !odd && r >= -D && r <= D && x+forward[offset+r] >= n {
+				return aHi - x, bHi - y, aHi - startX, bHi - startY
+			}
+		}
+	}
+	// not reached, as the paths overlap after at most maxD steps
+	return aLo, bLo, aLo, bLo
+}
+
+// deletionsFirst moves the deletions of each run of changes before its insertions.
+func deletionsFirst(script []DiffLine) []DiffLine {
+	for i := 0; i < len(script); {
+		if script[i].Op == DiffEqual {
+			i++
+			continue
+		}
+		end := i
+		for end < len(script) && script[end].Op != DiffEqual {
+			end++
+		}
+		slices.SortStableFunc(script[i:end], func(p, q DiffLine) int {
+			return cmp.Compare(p.Op, q.Op)
+		})
+		i = end
+	}
+	return script
+}
+
+// hunks groups an edit script into hunks with DiffContextLines of context.
+// Changes separated by no more than twice the context are joined in one hunk.
+func hunks(script []DiffLine) []Hunk {
+	// line numbers at each position of the script
+	origLine := make([]int, len(script)+1)
+	editLine := make([]int, len(script)+1)
+	origLine[0], editLine[0] = 1, 1
+	for i, l := range script {
+		origLine[i+1], editLine[i+1] = origLine[i], editLine[i]
+		if l.Op != DiffInsert {
+			origLine[i+1]++
+		}
+		if l.Op != DiffDelete {
+			editLine[i+1]++
+		}
+	}
+
+	var result []Hunk
+	for i := 0; i < len(script); {
+		if script[i].Op == DiffEqual {
+			i++
+			continue
+		}
+		end := i + 1
+		for j := end; j < len(script) && j-end <= 2*DiffContextLines; j++ {
+			if script[j].Op != DiffEqual {
+				end = j + 1
+			}
+		}
+		start := max(0, i-DiffContextLines)
+		stop := min(len(script), end+DiffContextLines)
+		result = append(result, Hunk{
+			OriginalStart: origLine[start],
+			OriginalLines: origLine[stop] - origLine[start],
+			EditedStart:   editLine[start],
+			EditedLines:   editLine[stop] - editLine[start],
+			Lines:         script[start:stop],
+		})
+		i = stop
+	}
+	return result
+}
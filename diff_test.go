@@ -0,0 +1,174 @@
+package editor
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		edited   string
+		want     string
+	}{
+		{
+			name:     "equal",
+			original: "a\nb\n",
+			edited:   "a\nb\n",
+			want:     "",
+		},
+		{
+			name:     "changed line",
+			original: "a\nb\nc\n",
+			edited:   "a\nB\nc\n",
+			want:     "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:     "insert into empty",
+			original: "",
+			edited:   "a\n",
+			want:     "@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:     "delete everything",
+			original: "a\nb\n",
+			edited:   "",
+			want:     "@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:     "missing newline at end",
+			original: "a\nb\n",
+			edited:   "a\nb",
+			want:     "@@ -1,2 +1,2 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			name:     "separate hunks",
+			original: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			edited:   "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			want:     "@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		{
+			name:     "joined hunks",
+			original: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			edited:   "one\n2\n3\n4\n5\n6\n7\neight\n",
+			want:     "@@ -1,8 +1,8 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := WriteDiff(buf, Diff([]byte(tt.original), []byte(tt.edited)), DiffOptions{}); err != nil {
+				t.Fatalf("WriteDiff() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("WriteDiff() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff_Minimal(t *testing.T) {
+	original := "a\nb\nc\na\nb\nb\na\n"
+	edited := "c\nb\na\nb\na\nc\n"
+	changes := 0
+	for _, h := range Diff([]byte(original), []byte(edited)) {
+		for _, l := range h.Lines {
+			if l.Op != DiffEqual {
+				changes++
+			}
+		}
+	}
+	if changes != 5 {
+		t.Errorf("Diff() changes = %d, want 5", changes)
+	}
+}
+
+func Test_diffLines_Random(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	lines := func() []string {
+		out := make([]string, rnd.IntN(30))
+		for i := range out {
+			out[i] = string(rune('a' + rnd.IntN(4)))
+		}
+		return out
+	}
+	for range 500 {
+		a, b := lines(), lines()
+		script := diffLines(a, b)
+
+		var gotA, gotB []string
+		changes := 0
+		for _, l := range script {
+			if l.Op != DiffInsert {
+				gotA = append(gotA, l.Text)
+			}
+			if l.Op != DiffDelete {
+				gotB = append(gotB, l.Text)
+			}
+			if l.Op != DiffEqual {
+				changes++
+			}
+		}
+		if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+			t.Fatalf("diffLines(%q, %q) = %v doesn't turn one into the other", a, b, script)
+		}
+		if want := len(a) + len(b) - 2*lcsLength(a, b); changes != want {
+			t.Fatalf("diffLines(%q, %q) has %d changes, want %d", a, b, changes, want)
+		}
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestDiff_LargeMemory(t *testing.T) {
+	original := &bytes.Buffer{}
+	edited := &bytes.Buffer{}
+	for i := range 5000 {
+		fmt.Fprintf(original, "line %d\n", i)
+		fmt.Fprintf(edited, "line %d\r\n", i)
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	hunks := Diff(original.Bytes(), edited.Bytes())
+	runtime.ReadMemStats(&after)
+	if len(hunks) != 1 || len(hunks[0].Lines) != 10000 {
+		t.Errorf("Diff() = %d hunks, want 1 of 10000 lines", len(hunks))
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 50<<20 {
+		t.Errorf("Diff() allocated %d MB", alloc>>20)
+	}
+}
+
+func TestWriteDiff_Color(t *testing.T) {
+	buf := &bytes.Buffer{}
+	opts := DiffOptions{OriginalName: "a", EditedName: "b", Color: true}
+	if err := WriteDiff(buf, Diff([]byte("x\n"), []byte("y\n")), opts); err != nil {
+		t.Fatalf("WriteDiff() error = %v", err)
+	}
+	for _, want := range []string{ansiBold + "--- a" + ansiReset, ansiRed + "-x" + ansiReset, ansiGreen + "+y" + ansiReset} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteDiff() = %q, want it to contain %q", buf.String(), want)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package editor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DirResult describes the files of a directory after editing them.
+type DirResult struct {
+	// Files holds the contents of all files by their slash-separated path in the directory.
+	Files map[string][]byte
+	// Added, Removed and Changed list the files which were created, deleted or modified.
+	Added   []string
+	Removed []string
+	Changed []string
+	// Renamed maps the old path to the new path of files which were moved without modification.
+	Renamed map[string]string
+}
+
+// Unchanged reports whether no file was added, removed, changed or renamed.
+func (r *DirResult) Unchanged() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0 && len(r.Renamed) == 0
+}
+
+// LaunchTempDir writes files, keyed by their slash-separated path, to a
+// temporary directory named with the given prefix, and opens them in the users
+// preferred editor, in one launch if its Profile allows, or else one after
+// another like LaunchFiles. The directory itself is opened if there are no
+// files. Editors which can browse the directory allow adding and removing files.
+//
+// Returns the files as the editor left them, the path to the temporary
+// directory so the caller can clean it up, and an error. Backup and swap files
+// of editors, like "file~" or ".file.swp", are ignored.
+func (e *BasicEditor) LaunchTempDir(prefix string, files map[string][]byte) (*DirResult, string, error) {
+	return e.LaunchTempDirContext(context.Background(), prefix, files)
+}
+
+// LaunchTempDirContext is like LaunchTempDir but stops before launching the
+// editor once the context is done.
+func (e *BasicEditor) LaunchTempDirContext(ctx context.Context, prefix string, files map[string][]byte) (*DirResult, string, error) {
+	dir, err := writeTempDir(prefix, files)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := e.launchDir(ctx, dir, files); err != nil {
+		return nil, dir, err
+	}
+	edited, err := readDir(dir)
+	if err != nil {
+		return nil, dir, err
+	}
+	return diffDir(files, edited, bytes.Equal), dir, nil
+}
+
+// launchDir opens the files written to dir, or dir itself if there are none.
+func (e *BasicEditor) launchDir(ctx context.Context, dir string, files map[string][]byte) error {
+	if len(files) == 0 {
+		return e.LaunchContext(ctx, dir)
+	}
+	var paths []string
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		paths = append(paths, filepath.Join(dir, filepath.FromSlash(name)))
+	}
+	return e.LaunchFilesContext(ctx, paths...)
+}
+
+// LaunchTempDir is like BasicEditor.LaunchTempDir, validating each file with
+// the schema for its path in FileSchemas, or else with Schema if it is set. The
+// editor is reopened until all files are valid, with the validation error of
+// each invalid file in a header. Like LaunchTempFile, the edits are checked for
+// being unchanged or empty, with all files removed or empty, files are cleaned
+// with StripComments and JSONC (for JSON files), and ConfirmFn is shown all
+// files. When not interactive, the editor isn't launched, and the files are
+// validated as given.
+func (e *ValidatingEditor) LaunchTempDir(prefix string, files map[string][]byte) (*DirResult, string, error) {
+	return e.LaunchTempDirContext(context.Background(), prefix, files)
+}
+
+// LaunchTempDirContext is like LaunchTempDir but stops before (re)launching the
+// editor once the context is done. Edits made so far are preserved.
+func (e *ValidatingEditor) LaunchTempDirContext(ctx context.Context, prefix string, files map[string][]byte) (*DirResult, string, error) {
+	if !e.interactive() {
+		return e.checkDir(ctx, files)
+	}
+
+	editor := e.BasicEditor.clone()
+	var (
+		edited   = files
+		headers  map[string][]byte
+		prevErrs map[string]error
+		dir      string
+		err      error
+	)
+	preserve := func(err error) (*DirResult, string, error) {
+		_, dir, err := e.preserve(ctx, nil, dir, err)
+		return diffDir(files, edited, e.equal), dir, err
+	}
+	cancel := func(reason string, err error) (*DirResult, string, error) {
+		os.RemoveAll(dir)
+		e.cancelled(ctx, reason, err)
+		return nil, "", err
+	}
+
+	for attempts := 1; ; attempts++ {
+		// explain the errors of invalid files at their top
+		headers = map[string][]byte{}
+		withHeaders := maps.Clone(edited)
+		for name, err := range prevErrs {
+			headers[name] = e.errorHeader(e.commentSyntaxFor(name), err)
+			withHeaders[name] = append(slices.Clip(headers[name]), edited[name]...)
+		}
+		if dir, err = writeTempDir(prefix, withHeaders); err != nil {
+			return nil, "", err
+		}
+
+		previous := edited
+		if err := editor.launchDir(ctx, dir, withHeaders); err != nil {
+			return preserve(err)
+		}
+		if edited, err = readDir(dir); err != nil {
+			edited = previous
+			return preserve(err)
+		}
+		for name, header := range headers {
+			if data, ok := edited[name]; ok {
+				edited[name], _ = stripErrorHeader(data, header)
+			}
+		}
+
+		// If we're retrying because of errors, and nothing was changed, short-circuit
+		if prevErrs != nil && maps.EqualFunc(previous, edited, e.equal) {
+			return preserve(e.InvalidFn(joinFileErrors(prevErrs)))
+		}
+
+		if maps.EqualFunc(files, edited, e.equal) {
+			cancelled, err := e.OriginalUnchangedFn()
+			if cancelled {
+				return cancel("unchanged", err)
+			}
+		}
+		empty, err := e.isEmptyDir(edited)
+		if err != nil {
+			return preserve(err)
+		}
+		if empty {
+			cancelled, err := e.EmptyFileFn()
+			if cancelled {
+				return cancel("empty", err)
+			}
+		}
+
+		var cleaned map[string][]byte
+		cleaned, prevErrs = e.validateDir(edited)
+		if prevErrs != nil {
+			e.validationFailed(ctx, attempts, joinFileErrors(prevErrs))
+			if e.MaxAttempts > 0 && attempts >= e.MaxAttempts {
+				return preserve(e.InvalidFn(joinFileErrors(prevErrs)))
+			}
+			os.RemoveAll(dir)
+			continue
+		}
+
+		// Let the user review their edits
+		if e.ConfirmFn != nil {
+			action, err := e.ConfirmFn(dirText(files), dirText(cleaned))
+			if err != nil {
+				return preserve(err)
+			}
+			switch action {
+			case ConfirmEditAgain:
+				os.RemoveAll(dir)
+				continue
+			case ConfirmDiscard:
+				return cancel("discarded", ErrEditing(errors.New(e.Messages.Get(MsgCancelledDiscarded))))
+			}
+		}
+
+		// Leave the files as the caller would expect them, without headers
+		for name, data := range cleaned {
+			if _, ok := headers[name]; ok || !bytes.Equal(data, edited[name]) {
+				if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), data, 0600); err != nil {
+					return preserve(err)
+				}
+			}
+		}
+		e.succeeded(ctx, dir, attempts)
+		return diffDir(files, cleaned, e.equal), dir, nil
+	}
+}
+
+// checkDir validates the files without editing them, for when the editor
+// isn't interactive.
+func (e *ValidatingEditor) checkDir(ctx context.Context, files map[string][]byte) (*DirResult, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	cleaned, errs := e.validateDir(files)
+	if errs != nil {
+		err := joinFileErrors(errs)
+		e.validationFailed(ctx, 1, err)
+		return nil, "", e.InvalidFn(err)
+	}
+	e.succeeded(ctx, "", 0)
+	return diffDir(files, cleaned, e.equal), "", nil
+}
+
+// isEmptyDir reports whether every file is empty, as decided by EmptyPredicate
+// or the comment syntax of the file, or there are no files.
+func (e *ValidatingEditor) isEmptyDir(files map[string][]byte) (bool, error) {
+	for name, data := range files {
+		empty := e.commentSyntaxFor(name).IsEmpty(data)
+		if e.EmptyPredicate != nil {
+			var err error
+			if empty, err = e.EmptyPredicate(data); err != nil {
+				return false, err
+			}
+		}
+		if !empty {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// validateDir cleans and validates each file, returning the cleaned files and
+// the errors of invalid files, or nil if all are valid.
+func (e *ValidatingEditor) validateDir(files map[string][]byte) (map[string][]byte, map[string]error) {
+	var (
+		cleaned = map[string][]byte{}
+		errs    map[string]error
+	)
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		result, validated := e.cleanFile(name, files[name])
+		cleaned[name] = result
+		if err := e.validateFile(name, validated); err != nil {
+			if errs == nil {
+				errs = map[string]error{}
+			}
+			errs[name] = err
+		}
+	}
+	return cleaned, errs
+}
+
+// cleanFile is like clean, with the comment syntax of the file, and JSONC only
+// applied to JSON files.
+func (e *ValidatingEditor) cleanFile(name string, data []byte) (result, validated []byte) {
+	ext := strings.ToLower(path.Ext(name))
+	return e.cleanWith(e.commentSyntaxFor(name), e.JSONC && (ext == ".json" || ext == ".jsonc"), data)
+}
+
+// validateFile checks for conflict markers if enabled, and then applies the
+// first schema in FileSchemas matching the path, or else Schema if it is set.
+func (e *ValidatingEditor) validateFile(name string, data []byte) error {
+	if e.RejectConflictMarkers && HasConflictMarkers(data) {
+		return ErrConflictMarkers
+	}
+	for _, pattern := range slices.Sorted(maps.Keys(e.FileSchemas)) {
+		if ok, _ := path.Match(pattern, name); ok {
+			return e.FileSchemas[pattern].ValidateBytes(data)
+		}
+	}
+	if e.Schema == nil {
+		return nil
+	}
+	return e.Schema.ValidateBytes(data)
+}
+
+// dirText shows files as one text for ConfirmFn, each after a "==> path <==" line.
+func dirText(files map[string][]byte) []byte {
+	buf := &bytes.Buffer{}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		fmt.Fprintf(buf, "==> %s <==\n", name)
+		buf.Write(files[name])
+		if data := files[name]; len(data) > 0 && data[len(data)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// commentSyntaxFor returns the comment syntax for a file's extension, or else
+// the editor's comment syntax.
+func (e *ValidatingEditor) commentSyntaxFor(name string) CommentSyntax {
+	if syntax, ok := CommentSyntaxFor(path.Ext(name)); ok {
+		return syntax
+	}
+	return e.commentSyntax()
+}
+
+// joinFileErrors joins the errors of files, prefixed by their path.
+func joinFileErrors(errs map[string]error) error {
+	var joined []error
+	for _, name := range slices.Sorted(maps.Keys(errs)) {
+		joined = append(joined, fmt.Errorf("%s: %w", name, errs[name]))
+	}
+	return errors.Join(joined...)
+}
+
+// writeTempDir creates a temporary directory holding the files.
+func writeTempDir(prefix string, files map[string][]byte) (string, error) {
+	dir, err := os.MkdirTemp("", prefix)
+	if err != nil {
+		return "", err
+	}
+	for name, data := range files {
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("file %q is outside the directory", name)
+		}
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+		if err := os.WriteFile(file, data, 0600); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// readDir reads the files in a directory, skipping backup and swap files.
+func readDir(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || isBackupFile(d.Name()) {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		files[filepath.ToSlash(rel)] = data
+		return err
+	})
+	return files, err
+}
+
+// isBackupFile reports whether a file name looks like an editor's backup or
+// swap file, like "file~", "#file#" or ".file.swp".
+func isBackupFile(name string) bool {
+	if strings.HasSuffix(name, "~") || strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#") {
+		return true
+	}
+	ext := path.Ext(name)
+	return strings.HasPrefix(name, ".") && len(ext) == 4 && strings.HasPrefix(ext, ".sw")
+}
+
+// diffDir compares the files before and after editing with equal. A removed
+// file with the same contents as an added file was renamed.
+func diffDir(before, after map[string][]byte, equal func(a, b []byte) bool) *DirResult {
+	res := &DirResult{Files: after, Renamed: map[string]string{}}
+	var added []string
+	for _, name := range slices.Sorted(maps.Keys(after)) {
+		old, ok := before[name]
+		switch {
+		case !ok:
+			added = append(added, name)
+		case !equal(old, after[name]):
+			res.Changed = append(res.Changed, name)
+		}
+	}
+	renamedTo := map[string]bool{}
+	for _, name := range slices.Sorted(maps.Keys(before)) {
+		if _, ok := after[name]; ok {
+			continue
+		}
+		i := slices.IndexFunc(added, func(a string) bool { return !renamedTo[a] && equal(before[name], after[a]) })
+		if i < 0 {
+			res.Removed = append(res.Removed, name)
+			continue
+		}
+		res.Renamed[name] = added[i]
+		renamedTo[added[i]] = true
+	}
+	for _, name := range added {
+		if !renamedTo[name] {
+			res.Added = append(res.Added, name)
+		}
+	}
+	return res
+}
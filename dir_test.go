@@ -0,0 +1,318 @@
+package editor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_diffDir(t *testing.T) {
+	tests := []struct {
+		name   string
+		before map[string][]byte
+		after  map[string][]byte
+		equal  func(a, b []byte) bool
+		want   *DirResult
+	}{
+		{
+			name:   "unchanged",
+			before: map[string][]byte{"a": []byte("1")},
+			after:  map[string][]byte{"a": []byte("1")},
+			want:   &DirResult{Renamed: map[string]string{}},
+		},
+		{
+			name:   "added, removed and changed",
+			before: map[string][]byte{"a": []byte("1"), "b": []byte("2")},
+			after:  map[string][]byte{"a": []byte("changed"), "c": []byte("3")},
+			want:   &DirResult{Added: []string{"c"}, Removed: []string{"b"}, Changed: []string{"a"}, Renamed: map[string]string{}},
+		},
+		{
+			name:   "renamed",
+			before: map[string][]byte{"a": []byte("1"), "b": []byte("2")},
+			after:  map[string][]byte{"dir/a": []byte("1"), "c": []byte("2"), "d": []byte("2")},
+			want:   &DirResult{Added: []string{"d"}, Renamed: map[string]string{"a": "dir/a", "b": "c"}},
+		},
+		{
+			name:   "compared with equal",
+			before: map[string][]byte{"a": []byte("1\n"), "b": []byte("2\n")},
+			after:  map[string][]byte{"a": []byte("1\r\n"), "c": []byte("2")},
+			equal:  IgnoreTrailingWhitespace,
+			want:   &DirResult{Renamed: map[string]string{"b": "c"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal := tt.equal
+			if equal == nil {
+				equal = bytes.Equal
+			}
+			got := diffDir(tt.before, tt.after, equal)
+			tt.want.Files = tt.after
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffDir() = %+v, want %+v", got, tt.want)
+			}
+			if got.Unchanged() != (tt.name == "unchanged") {
+				t.Errorf("Unchanged() = %v", got.Unchanged())
+			}
+		})
+	}
+}
+
+func Test_isBackupFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"config.yaml": false, ".env": false, ".swift": false,
+		"config.yaml~": true, "#config.yaml#": true, ".config.yaml.swp": true, ".config.yaml.swo": true,
+	} {
+		if got := isBackupFile(name); got != want {
+			t.Errorf("isBackupFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestBasicEditor_LaunchTempDir(t *testing.T) {
+	e := NewEditor()
+	e.Command = "vim"
+	e.LaunchFn = func(command, file string) error {
+		// the other files precede the last one in the command
+		dir := filepath.Dir(file)
+		if want := "vim -p " + filepath.Join(dir, "README") + " " + filepath.Join(dir, "conf", "a.json"); command != want {
+			return fmt.Errorf("command = %q, want %q", command, want)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "main.py"), []byte("print(2)\n"), 0600); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(dir, "conf", "a.json"), filepath.Join(dir, "conf", "b.json")); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, "main.py~"), []byte("print(1)\n"), 0600); err != nil {
+			return err
+		}
+		return os.Remove(filepath.Join(dir, "README"))
+	}
+	res, dir, err := e.LaunchTempDir("test-*", map[string][]byte{
+		"main.py":     []byte("print(1)\n"),
+		"conf/a.json": []byte("{}\n"),
+		"README":      []byte("readme\n"),
+	})
+	defer os.RemoveAll(dir)
+	if err != nil {
+		t.Fatalf("LaunchTempDir() error = %v", err)
+	}
+	want := &DirResult{
+		Files:   map[string][]byte{"main.py": []byte("print(2)\n"), "conf/b.json": []byte("{}\n")},
+		Removed: []string{"README"},
+		Changed: []string{"main.py"},
+		Renamed: map[string]string{"conf/a.json": "conf/b.json"},
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("LaunchTempDir() = %+v, want %+v", res, want)
+	}
+}
+
+func TestBasicEditor_LaunchTempDir_Sequential(t *testing.T) {
+	e := NewEditor()
+	e.Command = "notepad"
+	var launched []string
+	e.LaunchFn = func(command, file string) error {
+		if command != "notepad" {
+			return fmt.Errorf("command = %q", command)
+		}
+		launched = append(launched, filepath.Base(file))
+		return nil
+	}
+	_, dir, err := e.LaunchTempDir("test-*", map[string][]byte{"b.txt": nil, "a.txt": nil})
+	defer os.RemoveAll(dir)
+	if err != nil {
+		t.Fatalf("LaunchTempDir() error = %v", err)
+	}
+	if want := []string{"a.txt", "b.txt"}; !reflect.DeepEqual(launched, want) {
+		t.Errorf("LaunchTempDir() launched %q, want %q", launched, want)
+	}
+
+	launched = nil
+	_, dir, err = e.LaunchTempDir("test-*", nil)
+	defer os.RemoveAll(dir)
+	if err != nil {
+		t.Fatalf("LaunchTempDir() error = %v", err)
+	}
+	if len(launched) != 1 || !strings.HasPrefix(launched[0], "test-") {
+		t.Errorf("LaunchTempDir() launched %q, want the directory", launched)
+	}
+}
+
+func TestBasicEditor_LaunchTempDir_OutsideFile(t *testing.T) {
+	e := NewEditor()
+	e.LaunchFn = func(command, dir string) error { return nil }
+	if _, dir, err := e.LaunchTempDir("test-*", map[string][]byte{"../escape": nil}); err == nil {
+		os.RemoveAll(dir)
+		t.Error("LaunchTempDir() succeeded, want an error")
+	}
+}
+
+// jsonFileSchema requires valid JSON, reporting the error of the decoder.
+type jsonFileSchema struct{}
+
+func (jsonFileSchema) ValidateBytes(data []byte) error {
+	var v any
+	return json.Unmarshal(data, &v)
+}
+
+func TestValidatingEditor_LaunchTempDir(t *testing.T) {
+	tests := []struct {
+		name      string
+		edits     []map[string]string
+		wantFiles map[string]string
+		setup     func(e *ValidatingEditor)
+		wantErr   bool
+		wantShown string
+	}{
+		{
+			name: "valid after retry",
+			edits: []map[string]string{
+				{"conf/app.json": "{", "main.py": "print(2)\n"},
+				{"conf/app.json": "{}\n"},
+			},
+			wantFiles: map[string]string{"conf/app.json": "{}\n", "main.py": "print(2)\n"},
+			wantShown: "// " + msgValidationFailed + ":\n// unexpected end of JSON input\n//\n{",
+		},
+		{
+			name:    "unchanged",
+			edits:   []map[string]string{{}},
+			wantErr: true,
+		},
+		{
+			name:    "unchanged by EqualFn",
+			edits:   []map[string]string{{"main.py": "print(1)\r\n"}},
+			setup:   func(e *ValidatingEditor) { e.EqualFn = IgnoreTrailingWhitespace },
+			wantErr: true,
+		},
+		{
+			name:    "all removed",
+			edits:   []map[string]string{{"conf/app.json": "", "main.py": "# nothing\n"}},
+			wantErr: true,
+		},
+		{
+			name:      "comments stripped",
+			edits:     []map[string]string{{"conf/app.json": "// note\n{\"a\": 2,}\n", "main.py": "# note\nprint(2)\n"}},
+			setup:     func(e *ValidatingEditor) { e.StripComments, e.JSONC = true, true },
+			wantFiles: map[string]string{"conf/app.json": "{\"a\": 2 }\n", "main.py": "print(2)\n"},
+		},
+		{
+			name:  "confirmed",
+			edits: []map[string]string{{"main.py": "print(2)\n"}, {"main.py": "print(3)\n"}},
+			setup: func(e *ValidatingEditor) {
+				again := true
+				e.ConfirmFn = func(original, edited []byte) (ConfirmAction, error) {
+					if want := "==> conf/app.json <==\n{\"a\": 1}\n==> main.py <==\nprint(1)\n"; string(original) != want {
+						return ConfirmDiscard, fmt.Errorf("original = %q, want %q", original, want)
+					}
+					if again {
+						again = false
+						return ConfirmEditAgain, nil
+					}
+					return ConfirmApply, nil
+				}
+			},
+			wantFiles: map[string]string{"conf/app.json": "{\"a\": 1}\n", "main.py": "print(3)\n"},
+		},
+		{
+			name:  "discarded",
+			edits: []map[string]string{{"main.py": "print(2)\n"}},
+			setup: func(e *ValidatingEditor) {
+				e.ConfirmFn = func(original, edited []byte) (ConfirmAction, error) { return ConfirmDiscard, nil }
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid and then unchanged",
+			edits: []map[string]string{
+				{"conf/app.json": "{"},
+				{},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewValidatingEditor(nil)
+			e.ErrorHeader = true
+			e.Reporter = NopReporter{}
+			e.FileSchemas = map[string]Schema{"*/*.json": jsonFileSchema{}}
+			e.PreserveFileFn = func(data []byte, file string, err error) ([]byte, string, error) {
+				os.RemoveAll(file)
+				return data, "", err
+			}
+			e.Command = "vim"
+			if tt.setup != nil {
+				tt.setup(e)
+			}
+			var shown string
+			e.LaunchFn = func(command, file string) error {
+				if len(tt.edits) == 0 {
+					return fmt.Errorf("EDITOR_NEVER_EXITED")
+				}
+				dir := filepath.Dir(file)
+				data, _ := os.ReadFile(filepath.Join(dir, "conf", "app.json"))
+				shown = string(data)
+				for name, data := range tt.edits[0] {
+					if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(data), 0600); err != nil {
+						return err
+					}
+				}
+				tt.edits = tt.edits[1:]
+				return nil
+			}
+
+			res, dir, err := e.LaunchTempDir("test-*", map[string][]byte{
+				"conf/app.json": []byte("{\"a\": 1}\n"),
+				"main.py":       []byte("print(1)\n"),
+			})
+			defer os.RemoveAll(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LaunchTempDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(tt.edits) > 0 {
+				t.Errorf("LaunchTempDir() left %d edits", len(tt.edits))
+			}
+			if tt.wantErr {
+				return
+			}
+			got := map[string]string{}
+			for name, data := range res.Files {
+				got[name] = string(data)
+			}
+			if !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Errorf("LaunchTempDir() files = %q, want %q", got, tt.wantFiles)
+			}
+			if !strings.HasPrefix(shown, tt.wantShown) {
+				t.Errorf("LaunchTempDir() shown %q, want %q", shown, tt.wantShown)
+			}
+			for name, want := range tt.wantFiles {
+				if onDisk, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name))); string(onDisk) != want {
+					t.Errorf("LaunchTempDir() left %q on disk, want %q", onDisk, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidatingEditor_LaunchTempDir_NonInteractive(t *testing.T) {
+	e := NewValidatingEditor(nil)
+	e.Interaction = NonInteractive
+	e.Reporter = NopReporter{}
+	e.FileSchemas = map[string]Schema{"*.json": jsonFileSchema{}}
+	e.LaunchFn = func(command, file string) error { return fmt.Errorf("EDITOR_LAUNCHED") }
+
+	res, dir, err := e.LaunchTempDir("test-*", map[string][]byte{"app.json": []byte("{}\n")})
+	if err != nil || dir != "" || !res.Unchanged() {
+		t.Errorf("LaunchTempDir() = %+v, %q, %v, want the files unchanged", res, dir, err)
+	}
+	if _, _, err := e.LaunchTempDir("test-*", map[string][]byte{"app.json": []byte("{")}); err == nil {
+		t.Error("LaunchTempDir() succeeded with an invalid file")
+	}
+}
@@ -11,6 +11,16 @@ be useful to edit the application configuration or a system file, for example.
 	edit := editor.NewEditor()
 	err := edit.Launch("/etc/bashrc")
 
+BasicEditor and ValidatingEditor implement the Editor interface, and the Launcher interface for
+opening existing files, so your code can accept either or a fake in tests.
+
+To open several files in one editor, like tabs in vim, use LaunchFiles. The
+editor's Profile, found by its executable name, says how to pass them, and
+editors without one open the files one after another. Add profiles for other
+editors with RegisterProfile:
+
+	err := edit.LaunchFiles("values.yaml", "templates/deployment.yaml")
+
 # Arbitrary Data
 
 Most of the time, the data you want your user to edit isn't in an local file.
@@ -46,5 +56,198 @@ Simply create a schema and pass it to the editor:
 	edit := editor.NewValidatingEditor(schema)
 
 A schema must implement the Schema interface: https://godoc.org/github.com/confluentinc/go-editor#Schema
+
+Set ErrorHeader to show the validation error as a comment block at the top
+of the file when the editor is reopened. Line numbers in the error are shifted
+to match the reopened file, and the block is removed again before validating.
+Set MaxAttempts to give up after a number of invalid attempts.
+
+To validate with an existing tool, use a CommandSchema. The data is passed on
+stdin, or as a temporary file in place of "{}", and is invalid if the command
+fails or runs longer than its Timeout. Errorformat patterns turn its output into
+positioned errors for the temporary file:
+
+	schema := &editor.CommandSchema{
+		Command:   "promtool check config {}",
+		Extension: ".yml",
+		Patterns:  []string{"%f:%l:%c: %m"},
+	}
+
+Messages, like where cancelled edits were preserved, are shown with the
+editor's Reporter, which writes plain text to stdout by default. Use
+NewTextReporter with your own writers, NopReporter to stay silent, or implement
+Reporter to route them through your logger.
+
+Messages are looked up by ID in the editor's Messages catalog, which is chosen
+from the LC_ALL, LC_MESSAGES or LANG environment variables. Only English is
+built in; register translations with RegisterCatalog or set Messages directly.
+Missing messages fall back to English:
+
+	editor.RegisterCatalog("de", editor.Catalog{
+		editor.MsgCancelledEmptyFile: "Bearbeitung abgebrochen, leere Datei.",
+	})
+
+Set JSONC to let users leave comments and trailing commas in edited JSON. They
+are removed before the schema sees the data, keeping line numbers intact.
+
+A file is considered empty, cancelling the edit, if it only holds whitespace
+and comments. Set CommentSyntax to the syntax of your format, like CStyleComments or
+XMLComments, or use EmptyPredicate for your own rules. StripComments also removes
+comments from the edited data.
+
+By default, the edit is cancelled if the saved file is byte for byte the same
+as the original. Set EqualFn to compare semantically instead, with
+IgnoreTrailingWhitespace, IgnoreComments or JSONEqual.
+
+To let users review their changes before they are accepted, set ConfirmFn.
+NewDiffConfirm shows a unified diff and asks whether to apply the changes,
+edit again or discard them:
+
+	edit.ConfirmFn = editor.NewDiffConfirm(edit.Reporter, edit.Messages, true)
+
+The diff is also available on its own with Diff and WriteDiff.
+
+To show the original, the current version on a server or documentation next to
+the edited data, set Reference. It is written to a read-only temp file and
+opened beside the edited one, in editors which can split, like vim and VS Code.
+Only the edited file is read back and validated:
+
+	edit.Reference = &editor.Reference{Pattern: "original-*.yaml", Data: original}
+
+To observe editing sessions, set Hooks, which are called before and after the
+editor runs, on validation errors, and when editing is cancelled, preserved or
+succeeds. Logger takes a *slog.Logger for structured records of the same
+stages. Neither is set by default:
+
+	edit.Logger = slog.Default()
+	edit.Hooks.OnSuccess = func(file string, attempts int) {
+		metrics.Observe("edit_attempts", attempts)
+	}
+
+Instead of the data and path, EditTempFile returns a Result describing the session, including
+whether the data changed, how many attempts it took, whether the edits were
+preserved and the Diagnostics of a failed last attempt:
+
+	res, err := edit.EditTempFile(ctx, "example-*.yaml", bytes.NewReader(data))
+	if res.Preserved {
+		fmt.Println("your edits are in", res.Path)
+	}
+
+Where there is no terminal, like in CI, set Interaction to NonInteractive, or to
+AutoInteractive to decide by whether stdin is a terminal. The edited data is then
+read from Input, or stdin, and goes through the same checks as edits made in the
+editor. Instead of reopening the editor, a validation error ends editing, with
+its Diagnostics in the Result:
+
+	edit.Interaction = editor.AutoInteractive
+	edit.Input, err = os.Open(fromFile)
+
+To edit several documents at once, separated by "---" lines, use EditDocuments.
+Each document is validated on its own, and the editor isn't reopened for invalid
+ones. Apply the valid documents, and let the user fix the others with
+ReopenDocuments, which returns all documents again, keeping their Index:
+
+	docs, err := edit.EditDocuments(ctx, "resources-*.yaml", bytes.NewReader(data))
+	applied := map[int]bool{}
+	for err == nil {
+		for _, doc := range docs {
+			if doc.Err == nil && !applied[doc.Index] {
+				apply(doc.Data)
+				applied[doc.Index] = true
+			}
+		}
+		if editor.ValidDocuments(docs) {
+			break
+		}
+		docs, err = edit.ReopenDocuments(ctx, "resources-*.yaml", docs)
+	}
+
+To let users plan a batch of operations, like the todo list of git's
+interactive rebase, use EditList. Items are shown as "<verb> <id> <description>"
+lines, which can be reordered, removed or given another verb. Lines with
+unknown verbs or ids are marked with an error when the editor is reopened:
+
+	actions, err := edit.EditList(ctx, "topics-*.txt", items, []editor.ListVerb{
+		{Name: "keep", Short: "k", Help: "leave the topic"},
+		{Name: "delete", Short: "d", Help: "delete the topic"},
+	})
+
+To rename many items at once, like files with vidir, use EditNames. Each
+name is shown after a stable id, and removing its line deletes the item. Names
+given twice are rejected, and the result is a plan to apply, deletions first and
+renames in order. Swaps and other cycles go through a temporary name:
+
+	plan, err := edit.EditNames(ctx, "topics-*.txt", topics)
+	for _, name := range plan.Deletions {
+		deleteTopic(name)
+	}
+	for _, r := range plan.Renames {
+		renameTopic(r.Old, r.New)
+	}
+
+Objects made of several files can be edited as a directory with LaunchTempDir.
+The files are opened in one launch if the editor supports several files, or
+else one after another. The result lists the files which were added, removed,
+changed or renamed. With
+a ValidatingEditor, set FileSchemas to validate files by their path:
+
+	edit.FileSchemas = map[string]editor.Schema{"*.json": jsonSchema}
+	res, dir, err := edit.LaunchTempDir("function-*", map[string][]byte{
+		"handler.py":  code,
+		"config.json": config,
+	})
+	defer os.RemoveAll(dir)
+
+# Go Values
+
+To edit a Go value, pass it to Edit with a Codec. The edited data is decoded
+into a new value, rejecting unknown fields, and validated with its Validate
+method if it has one:
+
+	cfg, err := editor.Edit(ctx, editor.NewValidatingEditor(nil), cfg, editor.JSONCodec{})
+
+Codecs for JSON (with comments), .env, INI and properties files are included
+and can be looked up by name or file extension with LookupCodec and
+CodecForExtension. Register your own with RegisterCodec.
+
+# Versioned Resources
+
+To edit an object which may change while the editor is open, such as one
+fetched from an API, implement the Resource interface and use EditResource.
+If the update fails with ErrConflict, the latest version is fetched and the
+editor is reopened with the user's changes merged in, or with conflict markers:
+
+	data, err := edit.EditResource(ctx, "topic", resource)
+
+MemoryResource is an in-memory implementation for testing.
+
+The merge is a line-based three-way merge, available on its own as Merge3.
+Set RejectConflictMarkers to refuse edited data which still contains them.
+
+# Testing
+
+The editortest package provides a FakeEditor which plays back scripted edits
+instead of launching an editor, and records what it was shown:
+
+	fake := editortest.NewFakeEditor(editortest.Replace("invalid"), editortest.Append("fixed\n"))
+	edit.LaunchFn = fake.Edit
+	edit.ErrorHeader = true
+	...
+	fake.AssertLaunched(t, 2)
+	fake.AssertShownValidationHeader(t, 2)
+
+To test the real launch of an editor command end-to-end, ProcessEditor runs the
+test binary itself as a scripted fake editor. Call HelperMain first in TestMain:
+
+	pe := editortest.NewProcessEditor(t, `replace "valid\n"`)
+	edit.Command = pe.Command
+
+Record captures a real session of a ValidatingEditor, with what the editor was
+shown and saved on each attempt, in a diffable text format. Replay plays it back
+against an editor, for golden tests which catch changes in behavior:
+
+	golden := &editortest.Session{}
+	err := golden.UnmarshalText(data)
+	got := editortest.Replay(edit, "example-*.yaml", golden)
 */
 package editor
@@ -0,0 +1,181 @@
+package editor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DocumentSeparator is the line separating documents, like in YAML streams.
+const DocumentSeparator = "---"
+
+// DocumentResult is the outcome for one document of a multi-document edit.
+type DocumentResult struct {
+	// Index is the position of the document among the edited documents, starting at 0.
+	Index int
+	// Line is the line the document starts on, starting at 1, in the file it was last edited in.
+	Line int
+	// Data is the document, without separators, cleaned like with StripComments and JSONC.
+	Data []byte
+	// Err is the validation error of the document, with positions relative to the document.
+	Err error
+}
+
+// EditDocuments lets the user edit several documents at once, separated by
+// DocumentSeparator lines, and validates each document with the Schema. Empty
+// documents are skipped.
+//
+// Unlike LaunchTempFile, the editor isn't reopened when a document is invalid.
+// Instead, the results of all documents are returned, so the valid ones can be
+// applied, and the invalid ones can be edited again with ReopenDocuments.
+// The edited data is checked for being unchanged or empty like LaunchTempFile.
+func (e *ValidatingEditor) EditDocuments(ctx context.Context, prefix string, r io.Reader) ([]DocumentResult, error) {
+	original, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return e.editDocuments(ctx, prefix, original, nil)
+}
+
+// ReopenDocuments lets the user edit the invalid documents of a previous result
+// again, explaining their errors in a header whether or not ErrorHeader is set.
+// It returns all documents, with the edited ones in place of the invalid ones,
+// in order, keeping their Index. Documents the user added get the next indexes,
+// and invalid documents the user removed are left out. The given results are returned if none is invalid.
+func (e *ValidatingEditor) ReopenDocuments(ctx context.Context, prefix string, docs []DocumentResult) ([]DocumentResult, error) {
+	var (
+		failed [][]byte
+		reason = []string{e.Messages.Get(MsgValidationFailed) + ":"}
+		next   int
+	)
+	for _, doc := range docs {
+		next = max(next, doc.Index+1)
+		if doc.Err == nil {
+			continue
+		}
+		failed = append(failed, doc.Data)
+		reason = append(reason, e.Messages.Format(MsgDocumentFmt, len(failed)))
+		for _, line := range strings.Split(strings.TrimRight(doc.Err.Error(), "\n"), "\n") {
+			reason = append(reason, "  "+strings.TrimRight(line, " \t\r"))
+		}
+	}
+	if len(failed) == 0 {
+		return docs, nil
+	}
+	edited, err := e.editDocuments(ctx, prefix, JoinDocuments(failed), reason)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make([]DocumentResult, 0, len(docs)-len(failed)+len(edited))
+	for _, doc := range docs {
+		switch {
+		case doc.Err == nil:
+			merged = append(merged, doc)
+		case len(edited) > 0:
+			edited[0].Index = doc.Index
+			merged = append(merged, edited[0])
+			edited = edited[1:]
+		}
+	}
+	for _, doc := range edited {
+		doc.Index = next
+		next++
+		merged = append(merged, doc)
+	}
+	return merged, nil
+}
+
+// editDocuments edits the documents once, explained by a header for reason if
+// it isn't nil, and validates them one by one.
+func (e *ValidatingEditor) editDocuments(ctx context.Context, prefix string, original []byte, reason []string) ([]DocumentResult, error) {
+	// validate the documents separately, after the editing loop, and split
+	// them before cleaning, so they start at the lines the user saw
+	whole := *e
+	whole.Schema = acceptSchema{}
+	whole.StripComments = false
+	whole.JSONC = false
+	whole.ErrorHeader = true
+	res, err := whole.launchTempFile(ctx, prefix, original, original, reason)
+	if err != nil {
+		return nil, err
+	}
+	if res.Path != "" {
+		os.Remove(res.Path)
+	}
+
+	var docs []DocumentResult
+	for _, doc := range SplitDocuments(res.Data) {
+		result, validated := e.clean(doc.Data)
+		if len(bytes.TrimSpace(result)) == 0 {
+			continue
+		}
+		doc.Index, doc.Data = len(docs), result
+		if err := e.validate(validated); err != nil {
+			doc.Err = err
+			e.reporter().Error(fmt.Sprintf("%s: %v", e.Messages.Format(MsgDocumentInvalidFmt, doc.Index+1, doc.Line), err))
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// acceptSchema accepts any data, for edits which are validated after the
+// editing loop, like documents one by one.
+type acceptSchema struct{}
+
+func (acceptSchema) ValidateBytes([]byte) error { return nil }
+
+// SplitDocuments splits data at DocumentSeparator lines, skipping documents
+// which only hold whitespace.
+func SplitDocuments(data []byte) []DocumentResult {
+	var (
+		docs  []DocumentResult
+		doc   []byte
+		start = 1
+	)
+	add := func() {
+		if len(bytes.TrimSpace(doc)) > 0 {
+			docs = append(docs, DocumentResult{Index: len(docs), Line: start, Data: doc})
+		}
+		doc = nil
+	}
+	for i, line := range splitLines(data) {
+		if strings.TrimRight(line, " \t\r\n") == DocumentSeparator {
+			add()
+			start = i + 2
+			continue
+		}
+		doc = append(doc, line...)
+	}
+	add()
+	return docs
+}
+
+// JoinDocuments joins documents with DocumentSeparator lines.
+func JoinDocuments(docs [][]byte) []byte {
+	buf := &bytes.Buffer{}
+	for i, doc := range docs {
+		if i > 0 {
+			buf.WriteString(DocumentSeparator + "\n")
+		}
+		buf.Write(doc)
+		if len(doc) > 0 && doc[len(doc)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// ValidDocuments reports whether all documents passed validation.
+func ValidDocuments(docs []DocumentResult) bool {
+	for _, doc := range docs {
+		if doc.Err != nil {
+			return false
+		}
+	}
+	return true
+}
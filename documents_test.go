@@ -0,0 +1,158 @@
+package editor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitDocuments(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []DocumentResult
+	}{
+		{name: "empty"},
+		{name: "single", data: "a: 1\n", want: []DocumentResult{{Index: 0, Line: 1, Data: []byte("a: 1\n")}}},
+		{
+			name: "several",
+			data: "---\na: 1\n---  \r\nb: 2\nc: 3\n---\n\n---\nd: 4",
+			want: []DocumentResult{
+				{Index: 0, Line: 2, Data: []byte("a: 1\n")},
+				{Index: 1, Line: 4, Data: []byte("b: 2\nc: 3\n")},
+				{Index: 2, Line: 9, Data: []byte("d: 4")},
+			},
+		},
+		{name: "separator inside line", data: "a: ---\nb: '---'\n", want: []DocumentResult{{Line: 1, Data: []byte("a: ---\nb: '---'\n")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitDocuments([]byte(tt.data)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitDocuments() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinDocuments(t *testing.T) {
+	got := JoinDocuments([][]byte{[]byte("a: 1\n"), []byte("b: 2"), []byte("c: 3\n")})
+	if want := "a: 1\n---\nb: 2\n---\nc: 3\n"; string(got) != want {
+		t.Errorf("JoinDocuments() = %q, want %q", got, want)
+	}
+}
+
+// validDocSchema requires documents to start with "valid", ignoring leading
+// whitespace, like that left by comments.
+type validDocSchema struct{}
+
+func (validDocSchema) ValidateBytes(data []byte) error {
+	if !strings.HasPrefix(strings.TrimSpace(string(data)), "valid") {
+		return &PositionError{Line: 1, Column: 1, Err: errors.New("must start with valid")}
+	}
+	return nil
+}
+
+func TestValidatingEditor_EditDocuments(t *testing.T) {
+	e := NewValidatingEditor(validDocSchema{})
+	e.Reporter = NopReporter{}
+	var shown []string
+	edits := []string{
+		"valid one\n---\ninvalid two\n---\nvalid three\n---\ninvalid four\n",
+		"valid two\n---\ninvalid four\n",
+	}
+	e.LaunchFn = func(command, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		shown = append(shown, string(data))
+		err = os.WriteFile(file, []byte(edits[0]), 0600)
+		edits = edits[1:]
+		return err
+	}
+
+	docs, err := e.EditDocuments(context.Background(), "test", strings.NewReader("one\n---\ntwo\n"))
+	if err != nil {
+		t.Fatalf("EditDocuments() error = %v", err)
+	}
+	if len(docs) != 4 || ValidDocuments(docs) {
+		t.Fatalf("EditDocuments() = %+v, want 4 documents with errors", docs)
+	}
+	for i, wantErr := range []bool{false, true, false, true} {
+		if (docs[i].Err != nil) != wantErr {
+			t.Errorf("EditDocuments()[%d].Err = %v, wantErr %v", i, docs[i].Err, wantErr)
+		}
+	}
+	if docs[3].Line != 7 {
+		t.Errorf("EditDocuments()[3].Line = %d, want 7", docs[3].Line)
+	}
+
+	docs, err = e.ReopenDocuments(context.Background(), "test", docs)
+	if err != nil {
+		t.Fatalf("ReopenDocuments() error = %v", err)
+	}
+	wantShown := "# " + msgValidationFailed + ":\n" +
+		"# Document 1:\n" +
+		"#   line 1, column 1: must start with valid\n" +
+		"# Document 2:\n" +
+		"#   line 1, column 1: must start with valid\n" +
+		"#\n" +
+		"invalid two\n---\ninvalid four\n"
+	if len(shown) != 2 || shown[1] != wantShown {
+		t.Errorf("ReopenDocuments() shown %q, want %q", shown[len(shown)-1], wantShown)
+	}
+	if len(docs) != 4 || !ValidDocuments(docs[:3]) || string(docs[1].Data) != "valid two\n" || docs[3].Err == nil {
+		t.Errorf("ReopenDocuments() = %+v, want all documents with the invalid ones edited", docs)
+	}
+	for i, doc := range docs {
+		if doc.Index != i {
+			t.Errorf("ReopenDocuments()[%d].Index = %d", i, doc.Index)
+		}
+	}
+
+	if same, err := e.ReopenDocuments(context.Background(), "test", docs[:1]); err != nil || !reflect.DeepEqual(same, docs[:1]) {
+		t.Errorf("ReopenDocuments() of valid documents = %+v, %v", same, err)
+	}
+}
+
+func TestValidatingEditor_EditDocuments_StripComments(t *testing.T) {
+	e := NewValidatingEditor(validDocSchema{})
+	e.Reporter = NopReporter{}
+	e.StripComments = true
+	edits := []string{
+		"# note\nvalid one\n---\n# only a comment\n---\n# about two\ninvalid two\n",
+		"valid two\n---\nvalid five\n",
+	}
+	e.LaunchFn = func(command, file string) error {
+		err := os.WriteFile(file, []byte(edits[0]), 0600)
+		edits = edits[1:]
+		return err
+	}
+
+	docs, err := e.EditDocuments(context.Background(), "test", strings.NewReader("one\n"))
+	if err != nil {
+		t.Fatalf("EditDocuments() error = %v", err)
+	}
+	if len(docs) != 2 || string(docs[0].Data) != "valid one\n" || string(docs[1].Data) != "invalid two\n" {
+		t.Fatalf("EditDocuments() = %+v, want 2 cleaned documents", docs)
+	}
+	if docs[1].Line != 6 || docs[1].Index != 1 {
+		t.Errorf("EditDocuments()[1] starts on line %d with index %d, want line 6 and index 1", docs[1].Line, docs[1].Index)
+	}
+
+	docs, err = e.ReopenDocuments(context.Background(), "test", docs)
+	if err != nil {
+		t.Fatalf("ReopenDocuments() error = %v", err)
+	}
+	want := []DocumentResult{
+		{Index: 0, Line: 1, Data: []byte("valid one\n")},
+		{Index: 1, Line: 1, Data: []byte("valid two\n")},
+		{Index: 2, Line: 3, Data: []byte("valid five\n")},
+	}
+	if !reflect.DeepEqual(docs, want) {
+		t.Errorf("ReopenDocuments() = %+v, want %+v", docs, want)
+	}
+}
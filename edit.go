@@ -0,0 +1,97 @@
+package editor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Validator is implemented by edited values which can check themselves.
+type Validator interface {
+	Validate() error
+}
+
+// Edit lets the user edit a Go value in their preferred editor.
+//
+// The value is marshalled with the codec and the edited data is decoded into a
+// fresh T. If decoding fails, the editor's Schema rejects the data, or T (or *T)
+// implements Validator and Validate fails, the user is prompted to continue
+// editing just like with ValidatingEditor.LaunchTempFile. The editor's Schema may
+// be nil.
+//
+// The temp file is named with the codec's extension, and the codec's comment
+// syntax is used in place of the editor's. Errors are always explained in a
+// header, as with ErrorHeader. With JSONCodec, the editor's Schema receives the
+// data without comments, as with JSONC.
+//
+// The temp file is removed unless it was preserved because of an error.
+func Edit[T any](ctx context.Context, ed *ValidatingEditor, value T, codec Codec) (T, error) {
+	var zero T
+	data, err := codec.Marshal(value)
+	if err != nil {
+		return zero, err
+	}
+
+	schema := &codecSchema[T]{schema: ed.Schema, codec: codec}
+	e := *ed
+	e.Schema = schema
+	syntax := codec.CommentSyntax()
+	e.CommentSyntax = &syntax
+	e.ErrorHeader = true
+	if _, ok := codec.(JSONCodec); ok {
+		e.JSONC = true
+	}
+
+	_, file, err := e.LaunchTempFileContext(ctx, tempPrefix[T]()+codec.Extension(), bytes.NewReader(data))
+	if err != nil {
+		return zero, err
+	}
+	os.Remove(file)
+	return schema.value, nil
+}
+
+// tempPrefix names temp files after the edited type, like "config-*".
+func tempPrefix[T any]() string {
+	name := "edit"
+	if t := reflect.TypeFor[T](); t.Name() != "" {
+		name = strings.ToLower(t.Name())
+	}
+	return name + "-*"
+}
+
+// codecSchema validates edited data by decoding it into a T.
+type codecSchema[T any] struct {
+	schema Schema
+	codec  Codec
+	value  T
+}
+
+func (s *codecSchema[T]) ValidateBytes(data []byte) error {
+	v := new(T)
+	if err := s.codec.Unmarshal(data, v); err != nil {
+		return err
+	}
+	if s.schema != nil {
+		if err := s.schema.ValidateBytes(data); err != nil {
+			return err
+		}
+	}
+	if err := validate(v); err != nil {
+		return err
+	}
+	s.value = *v
+	return nil
+}
+
+// validate calls Validate on v or *v if either implements Validator.
+func validate[T any](v *T) error {
+	if val, ok := any(*v).(Validator); ok {
+		return val.Validate()
+	}
+	if val, ok := any(v).(Validator); ok {
+		return val.Validate()
+	}
+	return nil
+}
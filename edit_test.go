@@ -0,0 +1,121 @@
+package editor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type editConfig struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func (c editConfig) Validate() error {
+	if c.Count < 0 {
+		return errors.New("count must not be negative")
+	}
+	return nil
+}
+
+func TestEdit(t *testing.T) {
+	tests := []struct {
+		name       string
+		edited     []string
+		want       editConfig
+		wantErr    bool
+		wantShown  []string
+		wantLaunch int
+	}{
+		{
+			name:       "successful edit",
+			edited:     []string{`{"name": "b", "count": 2}`},
+			want:       editConfig{Name: "b", Count: 2},
+			wantLaunch: 1,
+		},
+		{
+			name:       "reopen on syntax error with position",
+			edited:     []string{"{\n  \"name\": \"b\",\n  \"count\": x\n}", `{"name": "c", "count": 3}`},
+			want:       editConfig{Name: "c", Count: 3},
+			wantShown:  []string{"", "// line 6, column 12: invalid character 'x'"},
+			wantLaunch: 2,
+		},
+		{
+			name:       "reopen on unknown field",
+			edited:     []string{`{"name": "b", "other": 2}`, `{"name": "c"}`},
+			want:       editConfig{Name: "c"},
+			wantShown:  []string{"", `// json: unknown field "other"`},
+			wantLaunch: 2,
+		},
+		{
+			name:       "reopen on failed Validate",
+			edited:     []string{`{"count": -1}`, `{"count": 1}`},
+			want:       editConfig{Count: 1},
+			wantShown:  []string{"", "// count must not be negative"},
+			wantLaunch: 2,
+		},
+		{
+			name:       "cancel when unchanged",
+			edited:     []string{"{\n  \"name\": \"a\",\n  \"count\": 1\n}\n"},
+			wantErr:    true,
+			wantLaunch: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewValidatingEditor(nil)
+			e.PreserveFileFn = func(data []byte, file string, err error) ([]byte, string, error) {
+				os.Remove(file)
+				return data, "", err
+			}
+			launches := 0
+			e.LaunchFn = func(command, file string) error {
+				if launches >= len(tt.edited) {
+					return fmt.Errorf("EDITOR_NEVER_EXITED")
+				}
+				shown, err := os.ReadFile(file)
+				if err != nil {
+					return err
+				}
+				if launches < len(tt.wantShown) && !strings.Contains(string(shown), tt.wantShown[launches]) {
+					t.Errorf("Edit() launch %d shown = %q, want it to contain %q", launches, shown, tt.wantShown[launches])
+				}
+				err = os.WriteFile(file, []byte(tt.edited[launches]), 0600)
+				launches++
+				return err
+			}
+			got, err := Edit(context.Background(), e, editConfig{Name: "a", Count: 1}, JSONCodec{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Edit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Edit() = %+v, want %+v", got, tt.want)
+			}
+			if launches != tt.wantLaunch {
+				t.Errorf("Edit() launches = %d, want %d", launches, tt.wantLaunch)
+			}
+		})
+	}
+}
+
+func TestEdit_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	e := NewValidatingEditor(nil)
+	e.PreserveFileFn = func(data []byte, file string, err error) ([]byte, string, error) {
+		os.Remove(file)
+		return data, "", err
+	}
+	e.LaunchFn = func(command, file string) error {
+		t.Fatal("Edit() launched the editor with a canceled context")
+		return nil
+	}
+	if _, err := Edit(ctx, e, editConfig{}, JSONCodec{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Edit() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -1,10 +1,17 @@
 package editor
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
+	"time"
+	"unicode"
 )
 
 var (
@@ -27,11 +34,22 @@ type BasicEditor struct {
 	Command string
 	// this is only for testing
 	LaunchFn func(command, file string) error
+
+	// Profile describes what the editor can do, like opening several files at once. Defaults to the profile for Command.
+	Profile *EditorProfile
+	// Reference is shown read-only next to the file edited by LaunchTempFile, in editors which can split.
+	Reference *Reference
+
+	// Hooks are called at each stage of editing.
+	Hooks Hooks
+	// Logger receives a record for each stage of editing. Nothing is logged if it is nil.
+	Logger *slog.Logger
 }
 
 // NewEditor launches an instance of the users preferred editor. The editor
 // to use is determined by reading the $VISUAL and $EDITOR environment variables.
 // If neither of these are present, vim or notepad (on Windows) is used.
+// The command may include arguments, like "code --wait".
 func NewEditor() *BasicEditor {
 	return &BasicEditor{
 		Command:  editor,
@@ -41,24 +59,95 @@ func NewEditor() *BasicEditor {
 
 func (e *BasicEditor) clone() *BasicEditor {
 	return &BasicEditor{
-		Command:  e.Command,
-		LaunchFn: e.LaunchFn,
+		Command:   e.Command,
+		LaunchFn:  e.LaunchFn,
+		Profile:   e.Profile,
+		Reference: e.Reference,
+		Hooks:     e.Hooks,
+		Logger:    e.Logger,
 	}
 }
 
 // Launch opens the given file path in the external editor or returns an error.
 func (e *BasicEditor) Launch(file string) error {
-	return e.LaunchFn(e.Command, file)
+	return e.LaunchContext(context.Background(), file)
+}
+
+// LaunchContext is like Launch but returns the context's error instead of
+// launching the editor once the context is done. An editor which is already
+// running is not interrupted.
+func (e *BasicEditor) LaunchContext(ctx context.Context, file string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e.beforeLaunch(ctx, file)
+	start := time.Now()
+	err := e.LaunchFn(e.Command, file)
+	e.afterLaunch(ctx, file, time.Since(start), err)
+	return err
 }
 
 func launch(command, file string) error {
-	cmd := exec.Command(command, file)
+	args := splitCommand(command)
+	if len(args) == 0 {
+		return errors.New("no editor command")
+	}
+	cmd := exec.Command(args[0], append(args[1:], file)...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
+// splitCommand splits a command line into words at unquoted whitespace, like a
+// shell, so editors can be given arguments, like "code --wait". Single and double
+// quotes group words, and a backslash escapes a following quote, backslash or
+// whitespace. A command naming an existing file is not split, for compatibility
+// with paths containing spaces.
+func splitCommand(command string) []string {
+	if _, err := os.Stat(command); err == nil {
+		return []string{command}
+	}
+	var (
+		args    []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	runes := []rune(command)
+	for i, r := range runes {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'' && i+1 < len(runes) && strings.ContainsRune("\\\"' \t", runes[i+1]):
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case unicode.IsSpace(r):
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args
+}
+
 // LaunchTempFile launches the users preferred editor on a temporary file.
 // This file is initialized with contents from the provided stream and named
 // with the given prefix.
@@ -68,28 +157,49 @@ func launch(command, file string) error {
 //
 // A file may be present even when an error is returned. Please clean it up.
 func (e *BasicEditor) LaunchTempFile(prefix string, r io.Reader) ([]byte, string, error) {
+	return e.LaunchTempFileContext(context.Background(), prefix, r)
+}
+
+// LaunchTempFileContext is like LaunchTempFile but stops before launching the
+// editor once the context is done.
+func (e *BasicEditor) LaunchTempFileContext(ctx context.Context, prefix string, r io.Reader) ([]byte, string, error) {
+	res, err := e.EditTempFile(ctx, prefix, r)
+	return res.Data, res.Path, err
+}
+
+// EditTempFile is like LaunchTempFileContext but describes the session with a
+// Result, which is never nil.
+func (e *BasicEditor) EditTempFile(ctx context.Context, prefix string, r io.Reader) (*Result, error) {
+	res := &Result{EditorCommand: e.Command}
+	start := time.Now()
+	defer func() { res.Duration = time.Since(start) }()
+
 	f, err := os.CreateTemp("", prefix)
 	if err != nil {
-		return nil, "", err
+		return res, err
 	}
 	defer f.Close()
 
 	// seed the editor with the initial temp file contents
-	if _, err := io.Copy(f, r); err != nil {
+	original := &bytes.Buffer{}
+	if _, err := io.Copy(f, io.TeeReader(r, original)); err != nil {
 		os.Remove(f.Name())
-		return nil, "", err
+		return res, err
 	}
 
 	// close the fd to prevent the editor being unable to save file
 	if err := f.Close(); err != nil {
-		return nil, "", err
+		return res, err
 	}
 
 	// launch the external editor on the temp file
-	if err := e.Launch(f.Name()); err != nil {
-		return nil, f.Name(), err
+	res.Path = f.Name()
+	res.Attempts++
+	if err := e.launchReference(ctx, f.Name()); err != nil {
+		return res, err
 	}
 
-	bytes, err := os.ReadFile(f.Name())
-	return bytes, f.Name(), err
+	res.Data, err = os.ReadFile(f.Name())
+	res.Changed = err == nil && !bytes.Equal(original.Bytes(), res.Data)
+	return res, err
 }
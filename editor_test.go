@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -116,3 +117,50 @@ func TestBasicEditor_LaunchTempFile(t *testing.T) {
 		})
 	}
 }
+
+func TestEditor(t *testing.T) {
+	appendLine := func(command, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(file, append(data, "edited\n"...), 0600)
+	}
+	basic := NewEditor()
+	basic.LaunchFn = appendLine
+	validating := NewValidatingEditor(&alwaysValidSchema{})
+	validating.LaunchFn = appendLine
+
+	for name, ed := range map[string]Editor{"basic": basic, "validating": validating} {
+		t.Run(name, func(t *testing.T) {
+			data, file, err := ed.LaunchTempFile("test", strings.NewReader("original\n"))
+			defer os.Remove(file)
+			if err != nil || string(data) != "original\nedited\n" {
+				t.Errorf("LaunchTempFile() = %q, %v", data, err)
+			}
+		})
+	}
+}
+
+func Test_splitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{command: "vim", want: []string{"vim"}},
+		{command: "  code   --wait ", want: []string{"code", "--wait"}},
+		{command: `"/path with spaces/editor" -f`, want: []string{"/path with spaces/editor", "-f"}},
+		{command: `emacs -nw --eval '(setq x "y")'`, want: []string{"emacs", "-nw", "--eval", `(setq x "y")`}},
+		{command: `my\ editor "a \"quoted\" arg"`, want: []string{"my editor", `a "quoted" arg`}},
+		{command: `C:\Windows\notepad.exe`, want: []string{`C:\Windows\notepad.exe`}},
+		{command: `editor ""`, want: []string{"editor", ""}},
+		{command: "", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := splitCommand(tt.command); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
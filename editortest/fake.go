@@ -0,0 +1,194 @@
+// Package editortest provides a scriptable fake editor for testing code which
+// uses go-editor, without launching a real editor.
+package editortest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/confluentinc/go-editor"
+)
+
+// ErrNoMoreSteps is returned when the editor is launched more often than scripted.
+var ErrNoMoreSteps = errors.New("fake editor launched more often than scripted")
+
+// Step is a scripted edit. It receives the contents of the file and returns the
+// contents to save, or nil to leave the file as it is.
+type Step func(data []byte) ([]byte, error)
+
+// ExitError is returned by a step which fails like an editor exiting with a
+// non-zero status.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// Replace saves s as the new contents.
+func Replace(s string) Step {
+	return func([]byte) ([]byte, error) { return []byte(s), nil }
+}
+
+// Append adds s to the end of the contents.
+func Append(s string) Step {
+	return func(data []byte) ([]byte, error) { return append(data, s...), nil }
+}
+
+// Apply saves the result of fn on the contents.
+func Apply(fn func(data []byte) []byte) Step {
+	return func(data []byte) ([]byte, error) { return fn(data), nil }
+}
+
+// Fail exits with the given status without saving.
+func Fail(code int) Step {
+	return func([]byte) ([]byte, error) { return nil, &ExitError{Code: code} }
+}
+
+// Abort quits without saving, like closing the editor without changes.
+func Abort() Step {
+	return func([]byte) ([]byte, error) { return nil, nil }
+}
+
+// FakeEditor is an editor.Editor which plays back scripted steps, one per
+// launch, instead of launching a command. It records the contents it was shown.
+//
+// Use its Edit method as the LaunchFn of another editor to script the edits
+// made during validation:
+//
+//	fake := editortest.NewFakeEditor(editortest.Replace("invalid"), editortest.Replace("valid"))
+//	edit := editor.NewValidatingEditor(schema)
+//	edit.LaunchFn = fake.Edit
+type FakeEditor struct {
+	*editor.BasicEditor
+
+	mu    sync.Mutex
+	steps []Step
+	shown []string
+	files []string
+}
+
+var _ editor.Editor = (*FakeEditor)(nil)
+
+// NewFakeEditor returns a FakeEditor which plays back the steps in order.
+func NewFakeEditor(steps ...Step) *FakeEditor {
+	f := &FakeEditor{steps: steps}
+	f.BasicEditor = &editor.BasicEditor{Command: "fake-editor", LaunchFn: f.Edit}
+	return f
+}
+
+// Edit plays back the next step on the file. It has the signature of LaunchFn.
+func (f *FakeEditor) Edit(command, file string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	f.shown = append(f.shown, string(data))
+	f.files = append(f.files, file)
+	if len(f.steps) == 0 {
+		return ErrNoMoreSteps
+	}
+	step := f.steps[0]
+	f.steps = f.steps[1:]
+
+	edited, err := step(data)
+	if err != nil {
+		return err
+	}
+	if edited == nil {
+		return nil
+	}
+	return os.WriteFile(file, edited, 0600)
+}
+
+// Launches returns how often the editor was launched.
+func (f *FakeEditor) Launches() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.shown)
+}
+
+// Shown returns the contents shown on each launch.
+func (f *FakeEditor) Shown() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.shown...)
+}
+
+// Files returns the file edited on each launch.
+func (f *FakeEditor) Files() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.files...)
+}
+
+// Remaining returns the number of steps not played back yet.
+func (f *FakeEditor) Remaining() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.steps)
+}
+
+// AssertLaunched fails the test unless the editor was launched n times.
+func (f *FakeEditor) AssertLaunched(t testing.TB, n int) {
+	t.Helper()
+	if got := f.Launches(); got != n {
+		t.Errorf("editor launched %d times, want %d", got, n)
+	}
+}
+
+// AssertShown fails the test unless the contents shown on the given launch,
+// starting at 1, are want.
+func (f *FakeEditor) AssertShown(t testing.TB, launch int, want string) {
+	t.Helper()
+	if got, ok := f.shownAt(t, launch); ok && got != want {
+		t.Errorf("launch %d shown %q, want %q", launch, got, want)
+	}
+}
+
+// AssertShownValidationHeader fails the test unless the contents shown on the
+// given launch, starting at 1, start with the header of a validation error in
+// English, mentioning each of the given messages.
+func (f *FakeEditor) AssertShownValidationHeader(t testing.TB, launch int, messages ...string) {
+	t.Helper()
+	got, ok := f.shownAt(t, launch)
+	if !ok {
+		return
+	}
+	first, _, _ := strings.Cut(got, "\n")
+	if !strings.Contains(first, editor.English.Get(editor.MsgValidationFailed)) {
+		t.Errorf("launch %d shown %q, want a validation error header", launch, got)
+		return
+	}
+	for _, msg := range messages {
+		if !strings.Contains(got, msg) {
+			t.Errorf("launch %d shown %q, want a validation error header with %q", launch, got, msg)
+		}
+	}
+}
+
+// AssertDone fails the test unless all steps were played back.
+func (f *FakeEditor) AssertDone(t testing.TB) {
+	t.Helper()
+	if n := f.Remaining(); n > 0 {
+		t.Errorf("%d scripted edits were not played back", n)
+	}
+}
+
+func (f *FakeEditor) shownAt(t testing.TB, launch int) (string, bool) {
+	t.Helper()
+	shown := f.Shown()
+	if launch < 1 || launch > len(shown) {
+		t.Errorf("editor launched %d times, want launch %d", len(shown), launch)
+		return "", false
+	}
+	return shown[launch-1], true
+}
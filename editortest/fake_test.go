@@ -0,0 +1,132 @@
+package editortest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/confluentinc/go-editor"
+)
+
+type prefixSchema struct {
+	prefix string
+}
+
+func (s *prefixSchema) ValidateBytes(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(s.prefix)) {
+		return fmt.Errorf("data missing prefix %q", s.prefix)
+	}
+	return nil
+}
+
+func TestFakeEditor_Validating(t *testing.T) {
+	fake := NewFakeEditor(
+		Replace("invalid\n"),
+		Apply(func(data []byte) []byte { return bytes.Replace(data, []byte("invalid"), []byte("valid"), 1) }),
+	)
+	edit := editor.NewValidatingEditor(&prefixSchema{prefix: "valid"})
+	edit.ErrorHeader = true
+	edit.Reporter = editor.NopReporter{}
+	edit.LaunchFn = fake.Edit
+
+	data, file, err := edit.LaunchTempFile("test", strings.NewReader("original\n"))
+	defer os.Remove(file)
+	if err != nil {
+		t.Fatalf("LaunchTempFile() error = %v", err)
+	}
+	if string(data) != "valid\n" {
+		t.Errorf("LaunchTempFile() = %q, want %q", data, "valid\n")
+	}
+	fake.AssertLaunched(t, 2)
+	fake.AssertShown(t, 1, "original\n")
+	fake.AssertShownValidationHeader(t, 2, `data missing prefix "valid"`)
+	fake.AssertDone(t)
+}
+
+func TestFakeEditor_Steps(t *testing.T) {
+	tests := []struct {
+		name     string
+		step     Step
+		wantData string
+		wantErr  error
+	}{
+		{name: "replace", step: Replace("new\n"), wantData: "new\n"},
+		{name: "append", step: Append("more\n"), wantData: "original\nmore\n"},
+		{name: "apply", step: Apply(bytes.ToUpper), wantData: "ORIGINAL\n"},
+		{name: "abort", step: Abort(), wantData: "original\n"},
+		{name: "fail", step: Fail(2), wantErr: &ExitError{Code: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFakeEditor(tt.step)
+			data, file, err := fake.LaunchTempFile("test", strings.NewReader("original\n"))
+			defer os.Remove(file)
+			var exitErr *ExitError
+			if tt.wantErr != nil {
+				if !errors.As(err, &exitErr) || err.Error() != tt.wantErr.Error() {
+					t.Errorf("LaunchTempFile() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || string(data) != tt.wantData {
+				t.Errorf("LaunchTempFile() = %q, %v, want %q", data, err, tt.wantData)
+			}
+			if got := fake.Files(); len(got) != 1 || got[0] != file {
+				t.Errorf("Files() = %q, want %q", got, file)
+			}
+		})
+	}
+}
+
+func TestFakeEditor_NoMoreSteps(t *testing.T) {
+	fake := NewFakeEditor()
+	_, file, err := fake.LaunchTempFile("test", strings.NewReader("original\n"))
+	defer os.Remove(file)
+	if !errors.Is(err, ErrNoMoreSteps) {
+		t.Errorf("LaunchTempFile() error = %v, want %v", err, ErrNoMoreSteps)
+	}
+}
+
+// recorder is a testing.TB which records failures instead of failing.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestFakeEditor_Assertions(t *testing.T) {
+	fake := NewFakeEditor(Replace("new\n"), Abort())
+	_, file, _ := fake.LaunchTempFile("test", strings.NewReader("original\n"))
+	defer os.Remove(file)
+
+	tests := []struct {
+		name   string
+		assert func(t testing.TB)
+		fail   bool
+	}{
+		{name: "launched", assert: func(t testing.TB) { fake.AssertLaunched(t, 1) }},
+		{name: "launched too often", assert: func(t testing.TB) { fake.AssertLaunched(t, 2) }, fail: true},
+		{name: "shown", assert: func(t testing.TB) { fake.AssertShown(t, 1, "original\n") }},
+		{name: "shown other", assert: func(t testing.TB) { fake.AssertShown(t, 1, "other\n") }, fail: true},
+		{name: "shown never launched", assert: func(t testing.TB) { fake.AssertShown(t, 2, "") }, fail: true},
+		{name: "no validation header", assert: func(t testing.TB) { fake.AssertShownValidationHeader(t, 1) }, fail: true},
+		{name: "not done", assert: func(t testing.TB) { fake.AssertDone(t) }, fail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			tt.assert(r)
+			if failed := len(r.errors) > 0; failed != tt.fail {
+				t.Errorf("assertion failed = %v %q, want %v", failed, r.errors, tt.fail)
+			}
+		})
+	}
+}
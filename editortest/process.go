@@ -0,0 +1,194 @@
+package editortest
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// processFlag marks a launch of the test binary as the fake editor, followed by
+// the directory holding its script and records.
+const processFlag = "-go-editor-fake="
+
+// ProcessEditor runs the test binary itself as a fake editor, so code using
+// go-editor can be tested end-to-end through exec.Command, without a real
+// editor installed. The test binary must call HelperMain first in TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		editortest.HelperMain()
+//		os.Exit(m.Run())
+//	}
+//
+// Each launch plays back the next line of a script, one of:
+//
+//	replace TEXT  saves TEXT, a Go quoted string, as the new contents
+//	append TEXT   adds TEXT, a Go quoted string, to the end of the contents
+//	abort         quits without saving
+//	exit CODE     quits with the exit status CODE without saving
+//
+// Empty lines and lines starting with "#" are ignored.
+type ProcessEditor struct {
+	// Command launches the fake editor. Use it as the Command of an editor.
+	Command string
+
+	dir string
+}
+
+// NewProcessEditor writes the script to a temporary directory, which is removed
+// when the test ends, and returns a ProcessEditor playing it back.
+func NewProcessEditor(t testing.TB, script string) *ProcessEditor {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("finding test binary: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "script"), []byte(script), 0600); err != nil {
+		t.Fatalf("writing script: %v", err)
+	}
+	return &ProcessEditor{
+		Command: fmt.Sprintf("%s %s", strconv.Quote(exe), strconv.Quote(processFlag+dir)),
+		dir:     dir,
+	}
+}
+
+// Launches returns how often the editor was launched.
+func (p *ProcessEditor) Launches() int {
+	n := 0
+	for ; ; n++ {
+		if _, err := os.Stat(p.record(n, "shown")); err != nil {
+			return n
+		}
+	}
+}
+
+// Shown returns the contents shown on each launch.
+func (p *ProcessEditor) Shown() []string {
+	var shown []string
+	for n := 0; n < p.Launches(); n++ {
+		data, _ := os.ReadFile(p.record(n, "shown"))
+		shown = append(shown, string(data))
+	}
+	return shown
+}
+
+// Args returns the arguments the editor was launched with, after the command.
+func (p *ProcessEditor) Args() [][]string {
+	var args [][]string
+	for n := 0; n < p.Launches(); n++ {
+		data, _ := os.ReadFile(p.record(n, "args"))
+		args = append(args, strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"))
+	}
+	return args
+}
+
+// AssertLaunched fails the test unless the editor was launched n times.
+func (p *ProcessEditor) AssertLaunched(t testing.TB, n int) {
+	t.Helper()
+	if got := p.Launches(); got != n {
+		t.Errorf("editor launched %d times, want %d", got, n)
+	}
+}
+
+func (p *ProcessEditor) record(n int, kind string) string {
+	return filepath.Join(p.dir, fmt.Sprintf("%s-%d", kind, n))
+}
+
+// HelperMain plays back the next step of a ProcessEditor script and exits if
+// the test binary was launched as a fake editor. Otherwise it returns at once.
+func HelperMain() {
+	if len(os.Args) < 2 || !strings.HasPrefix(os.Args[1], processFlag) {
+		return
+	}
+	p := &ProcessEditor{dir: strings.TrimPrefix(os.Args[1], processFlag)}
+	if err := p.play(os.Args[2:]); err != nil {
+		if exit, ok := err.(*ExitError); ok {
+			os.Exit(exit.Code)
+		}
+		fmt.Fprintf(os.Stderr, "fake editor: %v\n", err)
+		os.Exit(3)
+	}
+	os.Exit(0)
+}
+
+// play records the launch and applies the step for it to the last argument.
+func (p *ProcessEditor) play(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no file to edit")
+	}
+	file := args[len(args)-1]
+	steps, err := p.steps()
+	if err != nil {
+		return err
+	}
+
+	n := p.Launches()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(p.record(n, "args"), []byte(strings.Join(args, "\n")+"\n"), 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(p.record(n, "shown"), data, 0600); err != nil {
+		return err
+	}
+	if n >= len(steps) {
+		return ErrNoMoreSteps
+	}
+
+	edited, err := steps[n](data)
+	if err != nil || edited == nil {
+		return err
+	}
+	return os.WriteFile(file, edited, 0600)
+}
+
+// steps parses the script.
+func (p *ProcessEditor) steps() ([]Step, error) {
+	f, err := os.Open(filepath.Join(p.dir, "script"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var steps []Step
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		verb, arg, _ := strings.Cut(text, " ")
+		arg = strings.TrimSpace(arg)
+		var step Step
+		switch verb {
+		case "replace", "append":
+			s, err := strconv.Unquote(arg)
+			if err != nil {
+				return nil, fmt.Errorf("script line %d: %s needs a quoted string: %w", line, verb, err)
+			}
+			if verb == "replace" {
+				step = Replace(s)
+			} else {
+				step = Append(s)
+			}
+		case "abort":
+			step = Abort()
+		case "exit":
+			code, err := strconv.Atoi(arg)
+			if err != nil {
+				return nil, fmt.Errorf("script line %d: exit needs a status: %w", line, err)
+			}
+			step = Fail(code)
+		default:
+			return nil, fmt.Errorf("script line %d: unknown step %q", line, verb)
+		}
+		steps = append(steps, step)
+	}
+	return steps, scanner.Err()
+}
@@ -0,0 +1,81 @@
+package editortest
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/confluentinc/go-editor"
+)
+
+func TestMain(m *testing.M) {
+	HelperMain()
+	os.Exit(m.Run())
+}
+
+func TestProcessEditor_Validating(t *testing.T) {
+	pe := NewProcessEditor(t, `
+# first attempt fails validation
+replace "invalid\n"
+replace "valid\n"
+`)
+	edit := editor.NewValidatingEditor(&prefixSchema{prefix: "valid"})
+	edit.ErrorHeader = true
+	edit.Reporter = editor.NopReporter{}
+	edit.Command = pe.Command
+
+	data, file, err := edit.LaunchTempFile("test", strings.NewReader("original\n"))
+	defer os.Remove(file)
+	if err != nil {
+		t.Fatalf("LaunchTempFile() error = %v", err)
+	}
+	if string(data) != "valid\n" {
+		t.Errorf("LaunchTempFile() = %q, want %q", data, "valid\n")
+	}
+	pe.AssertLaunched(t, 2)
+	shown := pe.Shown()
+	if shown[0] != "original\n" || !strings.Contains(shown[1], `data missing prefix "valid"`) {
+		t.Errorf("Shown() = %q", shown)
+	}
+	if args := pe.Args(); len(args) != 2 || !reflect.DeepEqual(args[1], []string{file}) {
+		t.Errorf("Args() = %q, want the edited file", args)
+	}
+}
+
+func TestProcessEditor_Steps(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		wantData string
+		wantExit int
+	}{
+		{name: "append", script: `append "more\n"`, wantData: "original\nmore\n"},
+		{name: "abort", script: "abort", wantData: "original\n"},
+		{name: "exit", script: "exit 7", wantExit: 7},
+		{name: "no more steps", script: "", wantExit: 3},
+		{name: "invalid script", script: "replace unquoted", wantExit: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pe := NewProcessEditor(t, tt.script)
+			edit := editor.NewEditor()
+			edit.Command = pe.Command
+
+			data, file, err := edit.LaunchTempFile("test", strings.NewReader("original\n"))
+			defer os.Remove(file)
+			var exitErr *exec.ExitError
+			if tt.wantExit != 0 {
+				if !errors.As(err, &exitErr) || exitErr.ExitCode() != tt.wantExit {
+					t.Errorf("LaunchTempFile() error = %v, want exit status %d", err, tt.wantExit)
+				}
+				return
+			}
+			if err != nil || string(data) != tt.wantData {
+				t.Errorf("LaunchTempFile() = %q, %v, want %q", data, err, tt.wantData)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package editortest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/confluentinc/go-editor"
+)
+
+// Session is a recorded editing session of a ValidatingEditor, for golden tests
+// which detect changes in behavior, like when a schema changes.
+//
+// Sessions are marshaled to a diffable text format. Each section starts with
+// its name, followed by its contents with every line prefixed by "| ":
+//
+//	initial:
+//	| name: example
+//	attempt 1 shown:
+//	| name: example
+//	attempt 1 saved:
+//	| name: 42
+//	attempt 1 invalid:
+//	| name must be a string
+//	...
+//	result:
+//	| name: changed
+//
+// A line "\ No newline at end of section" follows contents without a final newline.
+type Session struct {
+	// Initial is the data the session started with.
+	Initial string
+	// Attempts are the launches of the editor.
+	Attempts []Attempt
+	// Result is the data returned when editing succeeded.
+	Result string
+	// Err is the error which ended editing, if any.
+	Err string
+}
+
+// Attempt is one launch of the editor in a Session.
+type Attempt struct {
+	// Shown is the file as the editor was launched on it, including any error header.
+	Shown string
+	// Saved is the file as the editor left it.
+	Saved string
+	// Failed is the error the editor exited with, if any.
+	Failed string
+	// Invalid is the validation error of the saved data, if any.
+	Invalid string
+}
+
+const noNewline = `\ No newline at end of section`
+
+// MarshalText encodes the session in the text format.
+func (s *Session) MarshalText() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	writeSection(buf, "initial", s.Initial)
+	for i, a := range s.Attempts {
+		name := "attempt " + strconv.Itoa(i+1)
+		writeSection(buf, name+" shown", a.Shown)
+		if a.Failed != "" {
+			writeSection(buf, name+" failed", a.Failed)
+			continue
+		}
+		writeSection(buf, name+" saved", a.Saved)
+		if a.Invalid != "" {
+			writeSection(buf, name+" invalid", a.Invalid)
+		}
+	}
+	if s.Err != "" {
+		writeSection(buf, "error", s.Err)
+	} else {
+		writeSection(buf, "result", s.Result)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeSection(buf *bytes.Buffer, name, text string) {
+	buf.WriteString(name + ":\n")
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line == "" {
+			continue
+		}
+		if l := strings.TrimSuffix(line, "\n"); l != "" {
+			buf.WriteString("| " + l + "\n")
+		} else {
+			buf.WriteString("|\n")
+		}
+		if !strings.HasSuffix(line, "\n") {
+			buf.WriteString(noNewline + "\n")
+		}
+	}
+}
+
+// UnmarshalText decodes a session in the text format.
+func (s *Session) UnmarshalText(data []byte) error {
+	*s = Session{}
+	var (
+		target *string
+		text   strings.Builder
+	)
+	flush := func() {
+		if target != nil {
+			*target = text.String()
+		}
+		text.Reset()
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		switch {
+		case line == "|" || strings.HasPrefix(line, "| "):
+			if target == nil {
+				return fmt.Errorf("line %d: contents outside a section", n)
+			}
+			text.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "|"), " ") + "\n")
+		case line == noNewline:
+			s := text.String()
+			text.Reset()
+			text.WriteString(strings.TrimSuffix(s, "\n"))
+		case strings.HasSuffix(line, ":"):
+			flush()
+			var err error
+			if target, err = s.section(strings.TrimSuffix(line, ":")); err != nil {
+				return fmt.Errorf("line %d: %w", n, err)
+			}
+		case strings.TrimSpace(line) == "":
+		default:
+			return fmt.Errorf("line %d: unexpected %q", n, line)
+		}
+	}
+	flush()
+	return scanner.Err()
+}
+
+// section returns the field holding the contents of the named section.
+func (s *Session) section(name string) (*string, error) {
+	switch name {
+	case "initial":
+		return &s.Initial, nil
+	case "result":
+		return &s.Result, nil
+	case "error":
+		return &s.Err, nil
+	}
+	var (
+		n    int
+		kind string
+	)
+	if _, err := fmt.Sscanf(name, "attempt %d %s", &n, &kind); err != nil || n < 1 {
+		return nil, fmt.Errorf("unknown section %q", name)
+	}
+	if n > len(s.Attempts)+1 {
+		return nil, fmt.Errorf("attempt %d follows attempt %d", n, len(s.Attempts))
+	}
+	if n > len(s.Attempts) {
+		s.Attempts = append(s.Attempts, Attempt{})
+	}
+	a := &s.Attempts[n-1]
+	switch kind {
+	case "shown":
+		return &a.Shown, nil
+	case "saved":
+		return &a.Saved, nil
+	case "failed":
+		return &a.Failed, nil
+	case "invalid":
+		return &a.Invalid, nil
+	}
+	return nil, fmt.Errorf("unknown section %q", name)
+}
+
+// Recorder records the editing sessions of a ValidatingEditor.
+type Recorder struct {
+	mu      sync.Mutex
+	session Session
+}
+
+// Record wraps the LaunchFn and Hooks of the editor to record its next session.
+// Hooks which are already set are still called.
+func Record(ed *editor.ValidatingEditor) *Recorder {
+	r := &Recorder{}
+	launch, hooks := ed.LaunchFn, ed.Hooks
+
+	ed.LaunchFn = func(command, file string) error {
+		shown, _ := os.ReadFile(file)
+		err := launch(command, file)
+		saved, _ := os.ReadFile(file)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if len(r.session.Attempts) == 0 {
+			r.session.Initial = string(shown)
+		}
+		a := Attempt{Shown: string(shown), Saved: string(saved)}
+		if err != nil {
+			a.Failed = err.Error()
+		}
+		r.session.Attempts = append(r.session.Attempts, a)
+		return err
+	}
+	ed.Hooks.OnValidationError = func(attempt int, err error) {
+		r.mu.Lock()
+		if n := len(r.session.Attempts); n > 0 {
+			r.session.Attempts[n-1].Invalid = err.Error()
+		}
+		r.mu.Unlock()
+		if hooks.OnValidationError != nil {
+			hooks.OnValidationError(attempt, err)
+		}
+	}
+	ed.Hooks.OnCancel = func(err error) {
+		r.end("cancelled", err)
+		if hooks.OnCancel != nil {
+			hooks.OnCancel(err)
+		}
+	}
+	ed.Hooks.OnPreserve = func(file string, err error) {
+		r.end("preserved", err)
+		if hooks.OnPreserve != nil {
+			hooks.OnPreserve(file, err)
+		}
+	}
+	ed.Hooks.OnSuccess = func(file string, attempts int) {
+		data, _ := os.ReadFile(file)
+		r.mu.Lock()
+		r.session.Result = string(data)
+		r.mu.Unlock()
+		if hooks.OnSuccess != nil {
+			hooks.OnSuccess(file, attempts)
+		}
+	}
+	return r
+}
+
+func (r *Recorder) end(reason string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.session.Err = reason
+	if err != nil {
+		r.session.Err = err.Error()
+	}
+}
+
+// Session returns a copy of the session recorded so far.
+func (r *Recorder) Session() *Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.session
+	s.Attempts = append([]Attempt(nil), s.Attempts...)
+	return &s
+}
+
+// Replay runs a copy of the editor on the initial data of the session, saving
+// the recorded buffers in turn instead of launching the editor, and returns the
+// session recorded on the way. It differs from the given session if the
+// editor, like its schema, behaves differently now. Preserved files are removed.
+func Replay(ed *editor.ValidatingEditor, prefix string, s *Session) *Session {
+	cp := *ed
+	basic := *ed.BasicEditor
+	cp.BasicEditor = &basic
+	cp.Interaction = editor.Interactive
+	cp.ConfirmFn = nil
+
+	var mu sync.Mutex
+	attempts := s.Attempts
+	cp.LaunchFn = func(command, file string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(attempts) == 0 {
+			return ErrNoMoreSteps
+		}
+		a := attempts[0]
+		attempts = attempts[1:]
+		if a.Failed != "" {
+			return errors.New(a.Failed)
+		}
+		return os.WriteFile(file, []byte(a.Saved), 0600)
+	}
+	cp.PreserveFileFn = func(data []byte, file string, err error) ([]byte, string, error) {
+		os.Remove(file)
+		return data, "", err
+	}
+	r := Record(&cp)
+
+	_, file, _ := cp.LaunchTempFile(prefix, strings.NewReader(s.Initial))
+	if file != "" {
+		os.Remove(file)
+	}
+	return r.Session()
+}
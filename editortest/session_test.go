@@ -0,0 +1,133 @@
+package editortest
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/confluentinc/go-editor"
+)
+
+func TestSession_MarshalText(t *testing.T) {
+	tests := []struct {
+		name    string
+		session Session
+		want    string
+	}{
+		{
+			name: "success after validation error",
+			session: Session{
+				Initial: "original\n",
+				Attempts: []Attempt{
+					{Shown: "original\n", Saved: "invalid\n\nend", Invalid: "data missing prefix"},
+					{Shown: "# error\ninvalid\n", Saved: "valid\n"},
+				},
+				Result: "valid\n",
+			},
+			want: `initial:
+| original
+attempt 1 shown:
+| original
+attempt 1 saved:
+| invalid
+|
+| end
+\ No newline at end of section
+attempt 1 invalid:
+| data missing prefix
+\ No newline at end of section
+attempt 2 shown:
+| # error
+| invalid
+attempt 2 saved:
+| valid
+result:
+| valid
+`,
+		},
+		{
+			name: "editor failed",
+			session: Session{
+				Initial:  "",
+				Attempts: []Attempt{{Shown: "", Failed: "exit status 1"}},
+				Err:      "exit status 1",
+			},
+			want: `initial:
+attempt 1 shown:
+attempt 1 failed:
+| exit status 1
+\ No newline at end of section
+error:
+| exit status 1
+\ No newline at end of section
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.session.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalText() = %s, want %s", got, tt.want)
+			}
+			var s Session
+			if err := s.UnmarshalText(got); err != nil {
+				t.Fatalf("UnmarshalText() error = %v", err)
+			}
+			if !reflect.DeepEqual(s, tt.session) {
+				t.Errorf("UnmarshalText() = %+v, want %+v", s, tt.session)
+			}
+		})
+	}
+}
+
+func TestSession_UnmarshalText_Errors(t *testing.T) {
+	for _, text := range []string{
+		"| outside\n",
+		"unknown:\n",
+		"attempt 2 shown:\n",
+		"attempt 1 other:\n",
+		"initial:\nnot contents\n",
+	} {
+		var s Session
+		if err := s.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q) succeeded, want an error", text)
+		}
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	newEditor := func(prefix string) *editor.ValidatingEditor {
+		ed := editor.NewValidatingEditor(&prefixSchema{prefix: prefix})
+		ed.Reporter = editor.NopReporter{}
+		return ed
+	}
+
+	ed := newEditor("valid")
+	fake := NewFakeEditor(Replace("invalid\n"), Replace("valid\n"))
+	ed.LaunchFn = fake.Edit
+	recorder := Record(ed)
+	_, file, err := ed.LaunchTempFile("test", strings.NewReader("original\n"))
+	os.Remove(file)
+	if err != nil {
+		t.Fatalf("LaunchTempFile() error = %v", err)
+	}
+	recorded := recorder.Session()
+	if len(recorded.Attempts) != 2 || recorded.Attempts[0].Invalid == "" || recorded.Result != "valid\n" {
+		t.Fatalf("Session() = %+v", recorded)
+	}
+
+	// the same schema behaves the same
+	if got := Replay(newEditor("valid"), "test", recorded); !reflect.DeepEqual(got, recorded) {
+		t.Errorf("Replay() = %+v, want %+v", got, recorded)
+	}
+
+	// a changed schema accepts the first attempt
+	got := Replay(newEditor("in"), "test", recorded)
+	if len(got.Attempts) != 1 || got.Result != "invalid\n" {
+		t.Errorf("Replay() with changed schema = %+v", got)
+	}
+}
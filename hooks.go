@@ -0,0 +1,83 @@
+package editor
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks are called at each stage of an editing session, for example to collect
+// metrics. Any of them may be nil.
+type Hooks struct {
+	// BeforeLaunch is called before the editor command is launched on a file.
+	BeforeLaunch func(command, file string)
+	// AfterLaunch is called when the editor exited, with how long it ran and its error, if any.
+	AfterLaunch func(command, file string, elapsed time.Duration, err error)
+	// OnValidationError is called when the edited data failed validation, with the attempt number starting at 1.
+	OnValidationError func(attempt int, err error)
+	// OnCancel is called when editing is cancelled, with the error returned to the caller, if any.
+	OnCancel func(err error)
+	// OnPreserve is called when the edits were preserved in a file after an error.
+	OnPreserve func(file string, err error)
+	// OnSuccess is called when the edits were accepted, with the number of times the editor was launched.
+	OnSuccess func(file string, attempts int)
+}
+
+func (e *BasicEditor) beforeLaunch(ctx context.Context, file string) {
+	if e.Hooks.BeforeLaunch != nil {
+		e.Hooks.BeforeLaunch(e.Command, file)
+	}
+	if e.Logger != nil {
+		e.Logger.DebugContext(ctx, "launching editor", "command", e.Command, "file", file)
+	}
+}
+
+func (e *BasicEditor) afterLaunch(ctx context.Context, file string, elapsed time.Duration, err error) {
+	if e.Hooks.AfterLaunch != nil {
+		e.Hooks.AfterLaunch(e.Command, file, elapsed, err)
+	}
+	if e.Logger != nil {
+		if err != nil {
+			e.Logger.WarnContext(ctx, "editor failed", "command", e.Command, "file", file, "duration", elapsed, "error", err)
+		} else {
+			e.Logger.InfoContext(ctx, "editor exited", "command", e.Command, "file", file, "duration", elapsed)
+		}
+	}
+}
+
+func (e *ValidatingEditor) validationFailed(ctx context.Context, attempt int, err error) {
+	if e.Hooks.OnValidationError != nil {
+		e.Hooks.OnValidationError(attempt, err)
+	}
+	if e.Logger != nil {
+		e.Logger.InfoContext(ctx, "validation failed", "attempt", attempt, "error", err)
+	}
+}
+
+func (e *ValidatingEditor) cancelled(ctx context.Context, reason string, err error) {
+	if e.Hooks.OnCancel != nil {
+		e.Hooks.OnCancel(err)
+	}
+	if e.Logger != nil {
+		e.Logger.InfoContext(ctx, "edit cancelled", "reason", reason, "error", err)
+	}
+}
+
+// preserve calls PreserveFileFn after notifying the hooks.
+func (e *ValidatingEditor) preserve(ctx context.Context, data []byte, file string, err error) ([]byte, string, error) {
+	if e.Hooks.OnPreserve != nil {
+		e.Hooks.OnPreserve(file, err)
+	}
+	if e.Logger != nil {
+		e.Logger.WarnContext(ctx, "edits preserved", "file", file, "error", err)
+	}
+	return e.PreserveFileFn(data, file, err)
+}
+
+func (e *ValidatingEditor) succeeded(ctx context.Context, file string, attempts int) {
+	if e.Hooks.OnSuccess != nil {
+		e.Hooks.OnSuccess(file, attempts)
+	}
+	if e.Logger != nil {
+		e.Logger.InfoContext(ctx, "edit succeeded", "file", file, "attempts", attempts)
+	}
+}
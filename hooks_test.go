@@ -0,0 +1,107 @@
+package editor
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidatingEditor_Hooks(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema Schema
+		edited []string
+		want   []string
+	}{
+		{
+			name:   "success after validation error",
+			schema: &compoundSchema{schemas: []Schema{&alwaysInvalidSchema{}, &alwaysValidSchema{}}},
+			edited: []string{"first\n", "second\n"},
+			want: []string{
+				"before", "after <nil>", "validation error 1: invalid",
+				"before", "after <nil>", "success 2",
+			},
+		},
+		{
+			name:   "cancel unchanged",
+			schema: &alwaysValidSchema{},
+			edited: []string{"original\n"},
+			want:   []string{"before", "after <nil>", "cancel " + msgCancelledNoOrigChanges},
+		},
+		{
+			name:   "preserve on editor error",
+			schema: &alwaysValidSchema{},
+			want:   []string{"before", "after EDITOR_FAILED", "preserve EDITOR_FAILED"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			e := NewValidatingEditor(tt.schema)
+			e.Reporter = NopReporter{}
+			e.Hooks = Hooks{
+				BeforeLaunch: func(command, file string) { got = append(got, "before") },
+				AfterLaunch: func(command, file string, elapsed time.Duration, err error) {
+					got = append(got, fmt.Sprintf("after %v", err))
+				},
+				OnValidationError: func(attempt int, err error) {
+					got = append(got, fmt.Sprintf("validation error %d: %v", attempt, err))
+				},
+				OnCancel:   func(err error) { got = append(got, fmt.Sprintf("cancel %v", err)) },
+				OnPreserve: func(file string, err error) { got = append(got, fmt.Sprintf("preserve %v", err)) },
+				OnSuccess:  func(file string, attempts int) { got = append(got, fmt.Sprintf("success %d", attempts)) },
+			}
+			editCount := 0
+			e.LaunchFn = func(command, file string) error {
+				if editCount >= len(tt.edited) {
+					return fmt.Errorf("EDITOR_FAILED")
+				}
+				err := os.WriteFile(file, []byte(tt.edited[editCount]), 0600)
+				editCount++
+				return err
+			}
+			_, file, _ := e.LaunchTempFile("test", strings.NewReader("original\n"))
+			if file != "" {
+				os.Remove(file)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hooks called = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatingEditor_Logger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	e := NewValidatingEditor(&compoundSchema{schemas: []Schema{&alwaysInvalidSchema{}, &alwaysValidSchema{}}})
+	e.Reporter = NopReporter{}
+	e.Logger = slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	edited := []string{"first\n", "second\n"}
+	e.LaunchFn = func(command, file string) error {
+		err := os.WriteFile(file, []byte(edited[0]), 0600)
+		edited = edited[1:]
+		return err
+	}
+	_, file, err := e.LaunchTempFile("test", strings.NewReader("original\n"))
+	if err != nil {
+		t.Fatalf("LaunchTempFile() error = %v", err)
+	}
+	os.Remove(file)
+
+	for _, want := range []string{
+		`msg="launching editor"`,
+		`msg="editor exited"`,
+		`msg="validation failed" attempt=1 error=invalid`,
+		`msg="edit succeeded"`,
+		`attempts=2`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log = %q, want %q", buf.String(), want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package editor
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// Interaction decides whether a ValidatingEditor launches the editor.
+type Interaction int
+
+const (
+	// Interactive launches the editor until the edited data is valid or editing is cancelled.
+	Interactive Interaction = iota
+	// NonInteractive reads the edited data from Input instead of launching the editor. It
+	// goes through the same checks as edits made in the editor, but only once, so a
+	// validation error ends editing.
+	NonInteractive
+	// AutoInteractive is NonInteractive when stdin is not a terminal, like in CI or scripts.
+	AutoInteractive
+)
+
+// interactive reports whether the editor should be launched.
+func (e *ValidatingEditor) interactive() bool {
+	switch e.Interaction {
+	case NonInteractive:
+		return false
+	case AutoInteractive:
+		return isTerminal(os.Stdin)
+	}
+	return true
+}
+
+// editInput checks the data from Input like edits made in the editor. The
+// Result holds the diagnostics if it is invalid.
+func (e *ValidatingEditor) editInput(ctx context.Context, original []byte) (*Result, error) {
+	res := &Result{}
+	start := time.Now()
+	defer func() { res.Duration = time.Since(start) }()
+
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	input := e.Input
+	if input == nil {
+		input = os.Stdin
+	}
+	edited, err := io.ReadAll(input)
+	if err != nil {
+		return res, err
+	}
+
+	if e.equal(original, edited) {
+		cancelled, err := e.OriginalUnchangedFn()
+		if cancelled {
+			e.cancelled(ctx, "unchanged", err)
+			return res, err
+		}
+	}
+	empty, err := e.isEmpty(edited)
+	if err != nil {
+		return res, err
+	}
+	if empty {
+		cancelled, err := e.EmptyFileFn()
+		if cancelled {
+			e.cancelled(ctx, "empty", err)
+			return res, err
+		}
+	}
+
+	result, validated := e.clean(edited)
+	if err := e.validate(validated); err != nil {
+		e.validationFailed(ctx, 1, err)
+		res.Diagnostics = Diagnostics(err)
+		return res, e.InvalidFn(err)
+	}
+
+	e.succeeded(ctx, "", 0)
+	res.Data = result
+	res.Changed = !e.equal(original, result)
+	return res, nil
+}
+
+// isTerminal reports whether f looks like a terminal: a character device other
+// than the null device.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(fi, null) {
+		return false
+	}
+	return true
+}
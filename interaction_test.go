@@ -0,0 +1,85 @@
+package editor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidatingEditor_NonInteractive(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    Schema
+		jsonc     bool
+		input     string
+		wantData  string
+		wantErr   string
+		wantDiags []Diagnostic
+	}{
+		{name: "valid", schema: &alwaysValidSchema{}, input: "edited\n", wantData: "edited\n"},
+		{name: "unchanged", schema: &alwaysValidSchema{}, input: "original\n", wantErr: msgCancelledNoOrigChanges},
+		{name: "empty", schema: &alwaysValidSchema{}, input: "# only a comment\n", wantErr: msgCancelledEmptyFile},
+		{
+			name:      "invalid",
+			schema:    errorSchema{&PositionError{Line: 1, Column: 3, Err: errors.New("bad")}},
+			input:     "edited\n",
+			wantErr:   msgCancelledNoValidChanges,
+			wantDiags: []Diagnostic{{Line: 1, Column: 3, Message: "bad"}},
+		},
+		{name: "jsonc", schema: &jsonSchema{}, jsonc: true, input: "{\n  // comment\n  \"a\": 1,\n}\n", wantData: "{\n  \"a\": 1\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewValidatingEditor(tt.schema)
+			e.Reporter = NopReporter{}
+			e.Interaction = NonInteractive
+			e.Input = strings.NewReader(tt.input)
+			e.JSONC = tt.jsonc
+			e.LaunchFn = func(command, file string) error {
+				t.Fatal("editor launched")
+				return nil
+			}
+
+			res, err := e.EditTempFile(context.Background(), "test", strings.NewReader("original\n"))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("EditTempFile() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("EditTempFile() error = %v", err)
+			}
+			if string(res.Data) != tt.wantData {
+				t.Errorf("EditTempFile() data = %q, want %q", res.Data, tt.wantData)
+			}
+			if res.Path != "" || res.Attempts != 0 {
+				t.Errorf("EditTempFile() path = %q, attempts = %d, want none", res.Path, res.Attempts)
+			}
+			if !reflect.DeepEqual(res.Diagnostics, tt.wantDiags) {
+				t.Errorf("EditTempFile() diagnostics = %v, want %v", res.Diagnostics, tt.wantDiags)
+			}
+		})
+	}
+}
+
+func Test_isTerminal(t *testing.T) {
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer null.Close()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	for name, f := range map[string]*os.File{"null device": null, "pipe": r} {
+		if isTerminal(f) {
+			t.Errorf("isTerminal(%s) = true, want false", name)
+		}
+	}
+}
@@ -1,5 +1,30 @@
 package editor
 
+import (
+	"context"
+	"io"
+)
+
+// Launcher is an interface for opening a file in an editor.
+type Launcher interface {
+	Launch(file string) error
+	LaunchContext(ctx context.Context, file string) error
+}
+
+// Editor is an interface for editing data in a temporary file. It is implemented
+// by BasicEditor and ValidatingEditor, so callers and tests can substitute their own.
+type Editor interface {
+	Launcher
+	LaunchTempFile(prefix string, r io.Reader) ([]byte, string, error)
+	LaunchTempFileContext(ctx context.Context, prefix string, r io.Reader) ([]byte, string, error)
+	EditTempFile(ctx context.Context, prefix string, r io.Reader) (*Result, error)
+}
+
+var (
+	_ Editor = (*BasicEditor)(nil)
+	_ Editor = (*ValidatingEditor)(nil)
+)
+
 // Schema is an interface for validating data.
 type Schema interface {
 	ValidateBytes(data []byte) error
@@ -13,3 +38,12 @@ type CancelEditingFn func() (bool, error)
 
 // PreserveFileFn is a function with which you can inspect the preserved file, edited data, and resulting error.
 type PreserveFileFn func(data []byte, file string, err error) ([]byte, string, error)
+
+// EmptyPredicate is a function with which you can decide whether edited data is (effectively) empty.
+type EmptyPredicate func(data []byte) (bool, error)
+
+// Comparator is a function with which you can decide whether edited data is unchanged.
+type Comparator func(a, b []byte) bool
+
+// ConfirmFn is a function with which you can review valid edits before they are accepted.
+type ConfirmFn func(original, edited []byte) (ConfirmAction, error)
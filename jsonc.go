@@ -0,0 +1,75 @@
+package editor
+
+// StripJSONC blanks out "//" and "/* */" comments and trailing commas in JSONC
+// data, leaving standard JSON. Every removed byte other than a newline is replaced
+// by a space, so line numbers and byte offsets in errors about the result still
+// match the original data.
+func StripJSONC(data []byte) []byte {
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	// blank out comments
+	for i := 0; i < len(out); i++ {
+		switch {
+		case out[i] == '"':
+			i = skipJSONString(out, i)
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n' && out[i] != '\r'; i++ {
+				out[i] = ' '
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			out[i], out[i+1] = ' ', ' '
+			for i += 2; i < len(out); i++ {
+				if out[i] == '*' && i+1 < len(out) && out[i+1] == '/' {
+					out[i], out[i+1] = ' ', ' '
+					i++
+					break
+				}
+				if out[i] != '\n' && out[i] != '\r' {
+					out[i] = ' '
+				}
+			}
+		}
+	}
+
+	// blank out commas followed only by whitespace and a closing bracket
+	for i := 0; i < len(out); i++ {
+		switch out[i] {
+		case '"':
+			i = skipJSONString(out, i)
+		case ',':
+			j := i + 1
+			for j < len(out) && isJSONSpace(out[j]) {
+				j++
+			}
+			if j < len(out) && (out[j] == '}' || out[j] == ']') {
+				out[i] = ' '
+			}
+		}
+	}
+	return out
+}
+
+// skipJSONString returns the index of the closing quote of the string starting at i.
+func skipJSONString(data []byte, i int) int {
+	for i++; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"', '\n':
+			return i
+		}
+	}
+	return i
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// CleanJSONC removes comments and trailing commas from JSONC data like StripJSONC,
+// but also drops the lines which only held comments and the whitespace left at the
+// end of lines. Line numbers are not preserved.
+func CleanJSONC(data []byte) []byte {
+	return dropBlankedLines(data, StripJSONC(data))
+}
@@ -0,0 +1,62 @@
+package editor
+
+import "testing"
+
+func TestStripJSONC(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "standard json",
+			data: `{"a": [1, 2]}`,
+			want: `{"a": [1, 2]}`,
+		},
+		{
+			name: "line comments",
+			data: "{\n  // comment\n  \"a\": 1 // trailing\n}",
+			want: "{\n            \n  \"a\": 1            \n}",
+		},
+		{
+			name: "block comments keep newlines",
+			data: "{/* one\ntwo */\"a\": 1}",
+			want: "{      \n      \"a\": 1}",
+		},
+		{
+			name: "comments in strings",
+			data: `{"a": "// not /* a */ comment \" //"}`,
+			want: `{"a": "// not /* a */ comment \" //"}`,
+		},
+		{
+			name: "trailing commas",
+			data: "{\"a\": [1, 2,\n], \"b\": \",}\",}",
+			want: "{\"a\": [1, 2 \n], \"b\": \",}\" }",
+		},
+		{
+			name: "trailing comma before comment",
+			data: "[1, // last\n]",
+			want: "[1         \n]",
+		},
+		{
+			name: "crlf",
+			data: "[1, // last\r\n]",
+			want: "[1         \r\n]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(StripJSONC([]byte(tt.data))); got != tt.want {
+				t.Errorf("StripJSONC() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanJSONC(t *testing.T) {
+	data := "{\n  // comment\n  \"a\": 1, // trailing\r\n  \"b\": [2,],\n}\n"
+	want := "{\n  \"a\": 1,\r\n  \"b\": [2 ]\n}\n"
+	if got := string(CleanJSONC([]byte(data))); got != want {
+		t.Errorf("CleanJSONC() = %q, want %q", got, want)
+	}
+}
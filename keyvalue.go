@@ -0,0 +1,512 @@
+package editor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvCodec edits values as KEY=value lines, like a .env file.
+//
+// Values may be a map[string]string or a struct of scalar fields, named by an
+// "env" tag or the field name. Values are quoted with Go syntax when needed and
+// may be unquoted, double-quoted or single-quoted when edited. An "export "
+// prefix is allowed.
+type EnvCodec struct{}
+
+// Extension returns ".env".
+func (EnvCodec) Extension() string { return ".env" }
+
+// CommentSyntax returns HashComments.
+func (EnvCodec) CommentSyntax() CommentSyntax { return HashComments }
+
+// Marshal returns v as KEY=value lines.
+func (EnvCodec) Marshal(v any) ([]byte, error) {
+	pairs, sections, err := marshalKeyValues(v, "env")
+	if err != nil {
+		return nil, err
+	}
+	if len(sections) > 0 {
+		return nil, fmt.Errorf("cannot marshal nested field %q as env", sections[0].name)
+	}
+	buf := &bytes.Buffer{}
+	for _, p := range pairs {
+		fmt.Fprintf(buf, "%s=%s\n", p.key, quoteValue(p.value, "#"))
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes KEY=value lines into v, rejecting unknown and duplicate keys.
+func (EnvCodec) Unmarshal(data []byte, v any) error {
+	target, err := newKeyValueTarget(v, "env")
+	if err != nil {
+		return err
+	}
+	return scanLines(data, func(line string) error {
+		if line == "" || strings.HasPrefix(line, "#") {
+			return nil
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("expected KEY=value")
+		}
+		value, err := unquoteValue(value, "#")
+		if err != nil {
+			return err
+		}
+		return target.set("", strings.TrimSpace(key), value)
+	})
+}
+
+// INICodec edits values as an INI file with [section] headers and key = value lines.
+//
+// Values may be a map[string]string, a map[string]map[string]string of sections,
+// where the "" section holds keys before the first header, or a struct. Scalar
+// struct fields are keys before the first header and struct fields are sections,
+// named by an "ini" tag or the field name.
+type INICodec struct{}
+
+// Extension returns ".ini".
+func (INICodec) Extension() string { return ".ini" }
+
+// CommentSyntax returns INIComments.
+func (INICodec) CommentSyntax() CommentSyntax { return INIComments }
+
+// Marshal returns v as an INI file.
+func (c INICodec) Marshal(v any) ([]byte, error) {
+	return marshalINI(v, "ini", " = ", c.CommentSyntax().Line, true)
+}
+
+// Unmarshal decodes an INI file into v, rejecting unknown and duplicate sections and keys.
+func (c INICodec) Unmarshal(data []byte, v any) error {
+	return unmarshalINI(data, v, "ini", "=", c.CommentSyntax().Line, true)
+}
+
+// PropertiesCodec edits values as a Java-style properties file of key=value or
+// key: value lines, without sections. Values are handled like INICodec.
+type PropertiesCodec struct{}
+
+// Extension returns ".properties".
+func (PropertiesCodec) Extension() string { return ".properties" }
+
+// CommentSyntax returns PropertiesComments.
+func (PropertiesCodec) CommentSyntax() CommentSyntax { return PropertiesComments }
+
+// Marshal returns v as a properties file.
+func (c PropertiesCodec) Marshal(v any) ([]byte, error) {
+	return marshalINI(v, "properties", "=", c.CommentSyntax().Line, false)
+}
+
+// Unmarshal decodes a properties file into v, rejecting unknown and duplicate keys.
+func (c PropertiesCodec) Unmarshal(data []byte, v any) error {
+	return unmarshalINI(data, v, "properties", "=:", c.CommentSyntax().Line, false)
+}
+
+func marshalINI(v any, tag, sep string, comments []string, sections bool) ([]byte, error) {
+	pairs, secs, err := marshalKeyValues(v, tag)
+	if err != nil {
+		return nil, err
+	}
+	if !sections && len(secs) > 0 {
+		return nil, fmt.Errorf("cannot marshal nested field %q as %s", secs[0].name, tag)
+	}
+	special := strings.Join(comments, "")
+	buf := &bytes.Buffer{}
+	for _, p := range pairs {
+		fmt.Fprintf(buf, "%s%s%s\n", p.key, sep, quoteValue(p.value, special))
+	}
+	for i, s := range secs {
+		if i > 0 || len(pairs) > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "[%s]\n", s.name)
+		for _, p := range s.pairs {
+			fmt.Fprintf(buf, "%s%s%s\n", p.key, sep, quoteValue(p.value, special))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshalINI(data []byte, v any, tag, seps string, comments []string, sections bool) error {
+	target, err := newKeyValueTarget(v, tag)
+	if err != nil {
+		return err
+	}
+	special := strings.Join(comments, "")
+	section := ""
+	return scanLines(data, func(line string) error {
+		if line == "" || strings.ContainsAny(line[:1], special) {
+			return nil
+		}
+		if sections && strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("expected ] at end of section header")
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			return target.section(section)
+		}
+		i := strings.IndexAny(line, seps)
+		if i < 0 {
+			return fmt.Errorf("expected key%svalue", seps[:1])
+		}
+		value, err := unquoteValue(line[i+1:], special)
+		if err != nil {
+			return err
+		}
+		return target.set(section, strings.TrimSpace(line[:i]), value)
+	})
+}
+
+// scanLines calls fn with each trimmed line of data, adding the line number to errors.
+func scanLines(data []byte, fn func(line string) error) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		if err := fn(strings.TrimSpace(scanner.Text())); err != nil {
+			return &PositionError{Line: n, Column: 1, Err: err}
+		}
+	}
+	return scanner.Err()
+}
+
+// quoteValue quotes a value with Go syntax if it wouldn't survive unquoteValue as is.
+func quoteValue(s, comments string) string {
+	if s == "" || s != strings.TrimSpace(s) || strings.ContainsAny(s, "\"'\n\r\\"+comments) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// unquoteValue parses a double-quoted (Go syntax), single-quoted (literal) or bare
+// value. Bare values end at a comment preceded by whitespace.
+func unquoteValue(s, comments string) (string, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, `"`):
+		prefix, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted value %s", s)
+		}
+		if err := checkTrailingComment(s[len(prefix):], comments); err != nil {
+			return "", err
+		}
+		return strconv.Unquote(prefix)
+	case strings.HasPrefix(s, "'"):
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("missing closing quote in %s", s)
+		}
+		if err := checkTrailingComment(s[end+2:], comments); err != nil {
+			return "", err
+		}
+		return s[1 : end+1], nil
+	}
+	for i := 1; i < len(s); i++ {
+		if strings.ContainsRune(comments, rune(s[i])) && (s[i-1] == ' ' || s[i-1] == '\t') {
+			return strings.TrimSpace(s[:i]), nil
+		}
+	}
+	return s, nil
+}
+
+func checkTrailingComment(rest, comments string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.ContainsAny(rest[:1], comments) {
+		return fmt.Errorf("unexpected %q after quoted value", rest)
+	}
+	return nil
+}
+
+type keyValue struct {
+	key   string
+	value string
+}
+
+type keyValueSection struct {
+	name  string
+	pairs []keyValue
+}
+
+// marshalKeyValues flattens v into top-level pairs and sections.
+func marshalKeyValues(v any, tag string) ([]keyValue, []keyValueSection, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil, nil
+		}
+		rv = rv.Elem()
+	}
+	switch {
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String && rv.Type().Elem().Kind() == reflect.String:
+		return marshalStringMap(rv), nil, nil
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String && rv.Type().Elem().Kind() == reflect.Map &&
+		rv.Type().Elem().Key().Kind() == reflect.String && rv.Type().Elem().Elem().Kind() == reflect.String:
+		var pairs []keyValue
+		var sections []keyValueSection
+		for _, name := range sortedKeys(rv) {
+			m := marshalStringMap(rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key())))
+			if name == "" {
+				pairs = m
+			} else {
+				sections = append(sections, keyValueSection{name: name, pairs: m})
+			}
+		}
+		return pairs, sections, nil
+	case rv.Kind() == reflect.Struct:
+		var pairs []keyValue
+		var sections []keyValueSection
+		for _, f := range structFields(rv, tag) {
+			if isScalar(f.value) {
+				s, err := formatScalar(f.value)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%s: %w", f.key, err)
+				}
+				pairs = append(pairs, keyValue{key: f.key, value: s})
+				continue
+			}
+			if f.value.Kind() != reflect.Struct {
+				return nil, nil, fmt.Errorf("%s: unsupported type %s", f.key, f.value.Type())
+			}
+			section := keyValueSection{name: f.key}
+			for _, sf := range structFields(f.value, tag) {
+				if !isScalar(sf.value) {
+					return nil, nil, fmt.Errorf("%s.%s: unsupported type %s", f.key, sf.key, sf.value.Type())
+				}
+				s, err := formatScalar(sf.value)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%s.%s: %w", f.key, sf.key, err)
+				}
+				section.pairs = append(section.pairs, keyValue{key: sf.key, value: s})
+			}
+			sections = append(sections, section)
+		}
+		return pairs, sections, nil
+	}
+	return nil, nil, fmt.Errorf("cannot marshal %s as %s", rv.Type(), tag)
+}
+
+func marshalStringMap(m reflect.Value) []keyValue {
+	var pairs []keyValue
+	for _, k := range sortedKeys(m) {
+		pairs = append(pairs, keyValue{key: k, value: m.MapIndex(reflect.ValueOf(k).Convert(m.Type().Key())).String()})
+	}
+	return pairs
+}
+
+func sortedKeys(m reflect.Value) []string {
+	keys := make([]string, 0, m.Len())
+	for _, k := range m.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type structField struct {
+	key   string
+	value reflect.Value
+}
+
+// structFields returns the exported fields of a struct, named by tag or field name.
+// Fields tagged "-" are skipped.
+func structFields(rv reflect.Value, tag string) []structField {
+	var fields []structField
+	for i := 0; i < rv.NumField(); i++ {
+		sf := rv.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key := sf.Name
+		if name, _, _ := strings.Cut(sf.Tag.Get(tag), ","); name == "-" {
+			continue
+		} else if name != "" {
+			key = name
+		}
+		fields = append(fields, structField{key: key, value: rv.Field(i)})
+	}
+	return fields
+}
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+func isScalar(v reflect.Value) bool {
+	if v.Type() == durationType || v.Type().Implements(textMarshalerType) || reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func formatScalar(v reflect.Value) (string, error) {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), nil
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
+
+func parseScalar(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", s)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// keyValueTarget assigns decoded keys to a map or struct.
+type keyValueTarget struct {
+	rv   reflect.Value
+	tag  string
+	seen map[string]bool
+}
+
+func newKeyValueTarget(v any, tag string) (*keyValueTarget, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil, fmt.Errorf("cannot unmarshal %s into non-pointer %T", tag, v)
+	}
+	rv = rv.Elem()
+	switch {
+	case rv.Kind() == reflect.Struct:
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String &&
+		(rv.Type().Elem().Kind() == reflect.String ||
+			rv.Type().Elem().Kind() == reflect.Map && rv.Type().Elem().Key().Kind() == reflect.String && rv.Type().Elem().Elem().Kind() == reflect.String):
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+	default:
+		return nil, fmt.Errorf("cannot unmarshal %s into %s", tag, rv.Type())
+	}
+	return &keyValueTarget{rv: rv, tag: tag, seen: map[string]bool{}}, nil
+}
+
+// section checks that a section can be assigned to.
+func (t *keyValueTarget) section(name string) error {
+	if t.seen["["+name] {
+		return fmt.Errorf("duplicate section %q", name)
+	}
+	t.seen["["+name] = true
+	_, err := t.sectionValue(name)
+	return err
+}
+
+func (t *keyValueTarget) sectionValue(name string) (reflect.Value, error) {
+	if t.rv.Kind() == reflect.Map && t.rv.Type().Elem().Kind() == reflect.Map {
+		key := reflect.ValueOf(name).Convert(t.rv.Type().Key())
+		m := t.rv.MapIndex(key)
+		if !m.IsValid() || m.IsNil() {
+			m = reflect.MakeMap(t.rv.Type().Elem())
+			t.rv.SetMapIndex(key, m)
+		}
+		return m, nil
+	}
+	if name == "" {
+		return t.rv, nil
+	}
+	if t.rv.Kind() == reflect.Struct {
+		for _, f := range structFields(t.rv, t.tag) {
+			if f.key == name && f.value.Kind() == reflect.Struct && !isScalar(f.value) {
+				return f.value, nil
+			}
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("unknown section %q", name)
+}
+
+func (t *keyValueTarget) set(section, key, value string) error {
+	if key == "" {
+		return errors.New("missing key")
+	}
+	id := section + "\x00" + key
+	if t.seen[id] {
+		return fmt.Errorf("duplicate key %q", key)
+	}
+	t.seen[id] = true
+
+	sv, err := t.sectionValue(section)
+	if err != nil {
+		return err
+	}
+	if sv.Kind() == reflect.Map {
+		sv.SetMapIndex(reflect.ValueOf(key).Convert(sv.Type().Key()), reflect.ValueOf(value).Convert(sv.Type().Elem()))
+		return nil
+	}
+	for _, f := range structFields(sv, t.tag) {
+		if f.key == key && isScalar(f.value) {
+			if err := parseScalar(f.value, value); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown key %q", key)
+}
@@ -0,0 +1,196 @@
+package editor
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type envConfig struct {
+	Host    string        `env:"HOST"`
+	Port    int           `env:"PORT"`
+	Debug   bool          `env:"DEBUG"`
+	Timeout time.Duration `env:"TIMEOUT"`
+	Ignored string        `env:"-"`
+}
+
+type iniConfig struct {
+	Name   string `ini:"name"`
+	Server struct {
+		Host string `ini:"host"`
+		Port uint16 `ini:"port"`
+	} `ini:"server"`
+}
+
+func TestEnvCodec(t *testing.T) {
+	in := envConfig{Host: "example.com", Port: 8080, Debug: true, Timeout: 5 * time.Second}
+	data, err := EnvCodec{}.Marshal(in)
+	if err != nil {
+		t.Fatalf("EnvCodec.Marshal() error = %v", err)
+	}
+	want := "HOST=example.com\nPORT=8080\nDEBUG=true\nTIMEOUT=5s\n"
+	if string(data) != want {
+		t.Errorf("EnvCodec.Marshal() = %q, want %q", data, want)
+	}
+	var out envConfig
+	if err := (EnvCodec{}).Unmarshal(data, &out); err != nil {
+		t.Fatalf("EnvCodec.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("EnvCodec.Unmarshal() = %+v, want %+v", out, in)
+	}
+}
+
+func TestEnvCodec_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     map[string]string
+		wantLine int
+	}{
+		{
+			name: "quoting and comments",
+			data: "# comment\nexport A=1\nB = \"two words\" # note\nC='it \"is\"'\nD=x # y\nE=a#b\n",
+			want: map[string]string{"A": "1", "B": "two words", "C": "it \"is\"", "D": "x", "E": "a#b"},
+		},
+		{
+			name: "escapes",
+			data: `A="line\nbreak"`,
+			want: map[string]string{"A": "line\nbreak"},
+		},
+		{
+			name:     "missing separator",
+			data:     "A=1\nB\n",
+			wantLine: 2,
+		},
+		{
+			name:     "duplicate key",
+			data:     "A=1\n\nA=2\n",
+			wantLine: 3,
+		},
+		{
+			name:     "unterminated quote",
+			data:     `A="x`,
+			wantLine: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+			err := EnvCodec{}.Unmarshal([]byte(tt.data), &got)
+			var posErr *PositionError
+			if errors.As(err, &posErr) != (tt.wantLine != 0) {
+				t.Fatalf("EnvCodec.Unmarshal() error = %v, want line %d", err, tt.wantLine)
+			}
+			if posErr != nil {
+				if posErr.Line != tt.wantLine {
+					t.Errorf("EnvCodec.Unmarshal() line = %d, want %d", posErr.Line, tt.wantLine)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EnvCodec.Unmarshal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvCodec_UnknownKey(t *testing.T) {
+	var got envConfig
+	err := EnvCodec{}.Unmarshal([]byte("HOST=a\nOTHER=b\n"), &got)
+	var posErr *PositionError
+	if !errors.As(err, &posErr) || posErr.Line != 2 {
+		t.Errorf("EnvCodec.Unmarshal() error = %v, want unknown key on line 2", err)
+	}
+}
+
+func TestINICodec(t *testing.T) {
+	var in iniConfig
+	in.Name = "; not a comment"
+	in.Server.Host = "localhost"
+	in.Server.Port = 9092
+	data, err := INICodec{}.Marshal(in)
+	if err != nil {
+		t.Fatalf("INICodec.Marshal() error = %v", err)
+	}
+	want := "name = \"; not a comment\"\n\n[server]\nhost = localhost\nport = 9092\n"
+	if string(data) != want {
+		t.Errorf("INICodec.Marshal() = %q, want %q", data, want)
+	}
+	var out iniConfig
+	if err := (INICodec{}).Unmarshal(data, &out); err != nil {
+		t.Fatalf("INICodec.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("INICodec.Unmarshal() = %+v, want %+v", out, in)
+	}
+}
+
+func TestINICodec_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     map[string]map[string]string
+		wantLine int
+	}{
+		{
+			name: "sections",
+			data: "top = 1\n; comment\n[a]\nx = 2\n# comment\n[b]\ny=3\n",
+			want: map[string]map[string]string{"": {"top": "1"}, "a": {"x": "2"}, "b": {"y": "3"}},
+		},
+		{
+			name:     "duplicate section",
+			data:     "[a]\n[a]\n",
+			wantLine: 2,
+		},
+		{
+			name:     "bad header",
+			data:     "[a\n",
+			wantLine: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]map[string]string
+			err := INICodec{}.Unmarshal([]byte(tt.data), &got)
+			var posErr *PositionError
+			if errors.As(err, &posErr) != (tt.wantLine != 0) {
+				t.Fatalf("INICodec.Unmarshal() error = %v, want line %d", err, tt.wantLine)
+			}
+			if posErr != nil {
+				if posErr.Line != tt.wantLine {
+					t.Errorf("INICodec.Unmarshal() line = %d, want %d", posErr.Line, tt.wantLine)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("INICodec.Unmarshal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestINICodec_UnknownSection(t *testing.T) {
+	var got iniConfig
+	err := INICodec{}.Unmarshal([]byte("name = a\n[client]\n"), &got)
+	var posErr *PositionError
+	if !errors.As(err, &posErr) || posErr.Line != 2 {
+		t.Errorf("INICodec.Unmarshal() error = %v, want unknown section on line 2", err)
+	}
+}
+
+func TestPropertiesCodec_Unmarshal(t *testing.T) {
+	var got map[string]string
+	data := "! comment\na=1\nb: 2\n# comment\n"
+	if err := (PropertiesCodec{}).Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("PropertiesCodec.Unmarshal() error = %v", err)
+	}
+	want := map[string]string{"a": "1", "b": "2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PropertiesCodec.Unmarshal() = %q, want %q", got, want)
+	}
+	if err := (PropertiesCodec{}).Unmarshal([]byte("[a]\n"), &got); err == nil {
+		t.Errorf("PropertiesCodec.Unmarshal() accepted a section header")
+	}
+}
@@ -0,0 +1,209 @@
+package editor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// listErrorMarker starts the comments pointing out errors in the line above.
+const listErrorMarker = "# ^ "
+
+var (
+	msgListCommands = "Commands:"
+	msgListOrder    = "These lines can be re-ordered; they are run from top to bottom."
+	msgListRemove   = "If you remove a line here, its item is skipped."
+
+	// listComments are the comments of an edited list, which are only
+	// recognized at the start of a line as descriptions may contain "#".
+	listComments = CommentSyntax{Line: []string{"#"}, LineStart: true}
+)
+
+// ListVerb is a verb which may be given to items edited with EditList, like
+// "pick" in the todo list of git's interactive rebase.
+type ListVerb struct {
+	Name string
+	// Short is an abbreviation of Name, like "p" for "pick". It's optional.
+	Short string
+	// Help describes the verb in the help block.
+	Help string
+}
+
+// ListItem is a line of a list edited with EditList.
+type ListItem struct {
+	Verb string
+	// ID identifies the item and can't contain whitespace.
+	ID string
+	// Description is shown to the user, who can't change it.
+	Description string
+}
+
+// EditList lets the user edit items as "<verb> <id> <description>" lines,
+// followed by a comment block explaining the verbs, like the todo list of git's
+// interactive rebase. Lines may be reordered, removed, or given another of the
+// verbs. Items without a verb are given the first one.
+//
+// It returns the remaining items in their new order, with the full name of
+// their verb and their original description. If lines have unknown verbs or
+// ids, or an id is repeated, the editor is reopened with an error below each of
+// them. The edited list is checked for being unchanged or empty like
+// LaunchTempFile, and the Schema isn't used.
+func (e *ValidatingEditor) EditList(ctx context.Context, prefix string, items []ListItem, verbs []ListVerb) ([]ListItem, error) {
+	if len(verbs) == 0 {
+		return nil, errors.New("no verbs for list")
+	}
+	items = slices.Clone(items)
+	byID := map[string]ListItem{}
+	for i, item := range items {
+		if item.ID == "" || strings.ContainsFunc(item.ID, unicode.IsSpace) {
+			return nil, fmt.Errorf("invalid list item id %q", item.ID)
+		}
+		if _, ok := byID[item.ID]; ok {
+			return nil, fmt.Errorf("duplicate list item id %q", item.ID)
+		}
+		if item.Verb == "" {
+			items[i].Verb = verbs[0].Name
+		} else if _, ok := lookupVerb(verbs, item.Verb); !ok {
+			return nil, fmt.Errorf("unknown verb %q of list item %q", item.Verb, item.ID)
+		}
+		byID[item.ID] = item
+	}
+
+	list := *e
+	list.Schema = acceptSchema{}
+	list.CommentSyntax = &listComments
+	list.StripComments = false
+	list.JSONC = false
+	list.EmptyPredicate = nil
+
+	original := e.renderList(items, verbs)
+	initial := original
+	for attempt := 1; ; attempt++ {
+		res, err := list.launchTempFile(ctx, prefix, original, initial, nil)
+		if err != nil {
+			return nil, err
+		}
+		result, err := parseList(res.Data, verbs, byID)
+		if err == nil {
+			os.Remove(res.Path)
+			return result, nil
+		}
+
+		e.validationFailed(ctx, attempt, err)
+		if !e.interactive() {
+			return nil, e.InvalidFn(err)
+		}
+		// give up if the errors weren't fixed, like LaunchTempFile
+		if bytes.Equal(res.Data, initial) || (e.MaxAttempts > 0 && attempt >= e.MaxAttempts) {
+			_, _, err = e.preserve(ctx, res.Data, res.Path, e.InvalidFn(err))
+			return nil, err
+		}
+		os.Remove(res.Path)
+		initial = annotateList(res.Data, err)
+	}
+}
+
+// renderList writes the items and the help block.
+func (e *ValidatingEditor) renderList(items []ListItem, verbs []ListVerb) []byte {
+	buf := &bytes.Buffer{}
+	for _, item := range items {
+		fmt.Fprintf(buf, "%s %s", item.Verb, item.ID)
+		if item.Description != "" {
+			fmt.Fprintf(buf, " %s", item.Description)
+		}
+		buf.WriteByte('\n')
+	}
+	fmt.Fprintf(buf, "\n# %s\n", e.Messages.Get(MsgListCommands))
+	for _, v := range verbs {
+		name := v.Name
+		if v.Short != "" {
+			name = v.Short + ", " + v.Name
+		}
+		if v.Help != "" {
+			fmt.Fprintf(buf, "# %s <id> = %s\n", name, v.Help)
+		} else {
+			fmt.Fprintf(buf, "# %s <id>\n", name)
+		}
+	}
+	fmt.Fprintf(buf, "#\n# %s\n# %s\n", e.Messages.Get(MsgListOrder), e.Messages.Get(MsgListRemove))
+	return buf.Bytes()
+}
+
+// parseList parses edited list lines, skipping blank lines and comments. Errors
+// are PositionErrors of the lines, joined.
+func parseList(data []byte, verbs []ListVerb, byID map[string]ListItem) ([]ListItem, error) {
+	var (
+		result []ListItem
+		errs   []error
+		seen   = map[string]bool{}
+	)
+	for i, line := range splitLines(data) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		fail := func(format string, args ...any) {
+			errs = append(errs, &PositionError{Line: i + 1, Err: fmt.Errorf(format, args...)})
+		}
+		if len(fields) < 2 {
+			fail("expected <verb> <id> <description>")
+			continue
+		}
+		verb, ok := lookupVerb(verbs, fields[0])
+		if !ok {
+			fail("unknown verb %q", fields[0])
+			continue
+		}
+		item, ok := byID[fields[1]]
+		switch {
+		case !ok:
+			fail("unknown id %q", fields[1])
+			continue
+		case seen[item.ID]:
+			fail("duplicate id %q", fields[1])
+			continue
+		}
+		seen[item.ID] = true
+		item.Verb = verb.Name
+		result = append(result, item)
+	}
+	return result, errors.Join(errs...)
+}
+
+func lookupVerb(verbs []ListVerb, name string) (ListVerb, bool) {
+	for _, v := range verbs {
+		if name == v.Name || (v.Short != "" && name == v.Short) {
+			return v, true
+		}
+	}
+	return ListVerb{}, false
+}
+
+// annotateList adds a comment with its errors below each invalid line, removing
+// those of a previous attempt.
+func annotateList(data []byte, err error) []byte {
+	lineErrs := map[int][]string{}
+	for _, d := range Diagnostics(err) {
+		lineErrs[d.Line] = append(lineErrs[d.Line], d.Message)
+	}
+
+	buf := &bytes.Buffer{}
+	for i, line := range splitLines(data) {
+		if strings.HasPrefix(line, listErrorMarker) {
+			continue
+		}
+		buf.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			buf.WriteByte('\n')
+		}
+		for _, msg := range lineErrs[i+1] {
+			buf.WriteString(listErrorMarker + msg + "\n")
+		}
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,203 @@
+package editor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testVerbs = []ListVerb{
+	{Name: "pick", Short: "p", Help: "apply the item"},
+	{Name: "drop", Short: "d", Help: "delete the item"},
+	{Name: "hold"},
+}
+
+var testItems = []ListItem{
+	{ID: "a1", Description: "First item"},
+	{ID: "b2", Description: "Second # item"},
+	{Verb: "hold", ID: "c3"},
+}
+
+func Test_parseList(t *testing.T) {
+	byID := map[string]ListItem{}
+	for _, item := range testItems {
+		byID[item.ID] = item
+	}
+	tests := []struct {
+		name    string
+		data    string
+		want    []ListItem
+		wantErr string
+	}{
+		{
+			name: "reordered",
+			data: "d b2 Changed description\n\n# comment\n  p a1\n",
+			want: []ListItem{
+				{Verb: "drop", ID: "b2", Description: "Second # item"},
+				{Verb: "pick", ID: "a1", Description: "First item"},
+			},
+		},
+		{
+			name: "all removed",
+			data: "# pick a1\n",
+		},
+		{
+			name:    "errors",
+			data:    "pick\npik a1\npick z9\nhold c3\ndrop c3\n",
+			wantErr: "line 1: expected <verb> <id> <description>\nline 2: unknown verb \"pik\"\nline 3: unknown id \"z9\"\nline 5: duplicate id \"c3\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseList([]byte(tt.data), testVerbs, byID)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parseList() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseList() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseList() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_annotateList(t *testing.T) {
+	data := "pik a1\n# ^ old error\npick z9"
+	_, err := parseList([]byte(data), testVerbs, map[string]ListItem{"a1": {ID: "a1"}})
+	want := "pik a1\n# ^ unknown verb \"pik\"\npick z9\n# ^ unknown id \"z9\"\n"
+	if got := string(annotateList([]byte(data), err)); got != want {
+		t.Errorf("annotateList() = %q, want %q", got, want)
+	}
+}
+
+func TestValidatingEditor_EditList(t *testing.T) {
+	e := NewValidatingEditor(nil)
+	e.Reporter = NopReporter{}
+	var shown []string
+	edits := []string{
+		"pick a1 First item\npik b2\n",
+		"pick a1 First item\npik b2\n# ^ unknown verb \"pik\"\nhold c3\n",
+		"pick a1 First item\n# ^ unknown verb \"pik\"\nhold c3\nd b2\n",
+	}
+	e.LaunchFn = func(command, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		shown = append(shown, string(data))
+		return os.WriteFile(file, []byte(edits[len(shown)-1]), 0600)
+	}
+
+	got, err := e.EditList(context.Background(), "todo", testItems, testVerbs)
+	if err != nil {
+		t.Fatalf("EditList() error = %v", err)
+	}
+	want := []ListItem{
+		{Verb: "pick", ID: "a1", Description: "First item"},
+		{Verb: "hold", ID: "c3"},
+		{Verb: "drop", ID: "b2", Description: "Second # item"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EditList() = %+v, want %+v", got, want)
+	}
+	if len(shown) != 3 {
+		t.Fatalf("editor launched %d times, want 3", len(shown))
+	}
+	wantFirst := `pick a1 First item
+pick b2 Second # item
+hold c3
+
+# Commands:
+# p, pick <id> = apply the item
+# d, drop <id> = delete the item
+# hold <id>
+#
+# These lines can be re-ordered; they are run from top to bottom.
+# If you remove a line here, its item is skipped.
+`
+	if shown[0] != wantFirst {
+		t.Errorf("first shown %q, want %q", shown[0], wantFirst)
+	}
+	if want := "pick a1 First item\npik b2\n# ^ unknown verb \"pik\"\n"; shown[1] != want {
+		t.Errorf("second shown %q, want %q", shown[1], want)
+	}
+	if testItems[0].Verb != "" {
+		t.Errorf("EditList() changed the given items")
+	}
+}
+
+func TestValidatingEditor_EditList_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		edits       []string
+		wantShown   int
+	}{
+		{
+			name:      "unchanged after error",
+			edits:     []string{"pik a1\n", "pik a1\n# ^ unknown verb \"pik\"\n"},
+			wantShown: 2,
+		},
+		{
+			name:        "max attempts",
+			maxAttempts: 2,
+			edits:       []string{"pik a1\n", "pick z9\n"},
+			wantShown:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewValidatingEditor(nil)
+			e.Reporter = NopReporter{}
+			e.MaxAttempts = tt.maxAttempts
+			var shown int
+			var preserved string
+			e.PreserveFileFn = func(data []byte, file string, err error) ([]byte, string, error) {
+				preserved = string(data)
+				os.Remove(file)
+				return data, file, err
+			}
+			e.LaunchFn = func(command, file string) error {
+				shown++
+				return os.WriteFile(file, []byte(tt.edits[shown-1]), 0600)
+			}
+			got, err := e.EditList(context.Background(), "todo", testItems, testVerbs)
+			if err == nil || got != nil {
+				t.Fatalf("EditList() = %v, %v, want an error", got, err)
+			}
+			if shown != tt.wantShown {
+				t.Errorf("editor launched %d times, want %d", shown, tt.wantShown)
+			}
+			if preserved != tt.edits[len(tt.edits)-1] {
+				t.Errorf("preserved %q, want the last edit", preserved)
+			}
+		})
+	}
+}
+
+func TestValidatingEditor_EditList_NonInteractive(t *testing.T) {
+	e := NewValidatingEditor(nil)
+	e.Reporter = NopReporter{}
+	e.Interaction = NonInteractive
+	e.Input = bytes.NewBufferString("drop c3\np a1\n")
+	got, err := e.EditList(context.Background(), "todo", testItems, testVerbs)
+	if err != nil {
+		t.Fatalf("EditList() error = %v", err)
+	}
+	if ids := []string{got[0].Verb + " " + got[0].ID, got[1].Verb + " " + got[1].ID}; strings.Join(ids, ",") != "drop c3,pick a1" {
+		t.Errorf("EditList() = %+v", got)
+	}
+
+	e.Input = bytes.NewBufferString("pik a1\n")
+	if _, err := e.EditList(context.Background(), "todo", testItems, testVerbs); err == nil {
+		t.Errorf("EditList() with an unknown verb succeeded")
+	}
+}
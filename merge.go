@@ -0,0 +1,144 @@
+package editor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	conflictStart  = "<<<<<<<"
+	conflictMiddle = "======="
+	conflictEnd    = ">>>>>>>"
+)
+
+// ErrConflictMarkers is returned when edited data still contains conflict markers.
+var ErrConflictMarkers = errors.New("unresolved conflict markers")
+
+// MergeLabels name the versions in conflict markers.
+type MergeLabels struct {
+	Ours   string
+	Theirs string
+}
+
+// Merge3 merges the changes from base to ours and from base to theirs, line by
+// line, like diff3. Changes to different lines are combined. Where both changed
+// the same lines differently, both versions are kept between conflict markers:
+//
+//	<<<<<<< ours
+//	...
+//	=======
+//	...
+//	>>>>>>> theirs
+//
+// Returns the merged data and the number of conflicts.
+func Merge3(base, ours, theirs []byte, labels MergeLabels) ([]byte, int) {
+	baseLines := splitLines(base)
+	oursLines := splitLines(ours)
+	theirsLines := splitLines(theirs)
+	oursMatch := matchLines(diffLines(baseLines, oursLines), len(baseLines))
+	theirsMatch := matchLines(diffLines(baseLines, theirsLines), len(baseLines))
+
+	buf := &bytes.Buffer{}
+	conflicts := 0
+	i, o, t := 0, 0, 0 // positions in base, ours and theirs
+	for i <= len(baseLines) {
+		// copy base lines unchanged on both sides
+		if i < len(baseLines) && oursMatch[i] == o && theirsMatch[i] == t {
+			buf.WriteString(baseLines[i])
+			i, o, t = i+1, o+1, t+1
+			continue
+		}
+
+		// find the end of the unstable chunk: the next base line which is
+		// kept on both sides, or the end of all of them
+		end := i
+		for end < len(baseLines) && (oursMatch[end] < 0 || theirsMatch[end] < 0) {
+			end++
+		}
+		oEnd, tEnd := len(oursLines), len(theirsLines)
+		if end < len(baseLines) {
+			oEnd, tEnd = oursMatch[end], theirsMatch[end]
+		}
+
+		baseChunk := baseLines[i:end]
+		oursChunk := oursLines[o:oEnd]
+		theirsChunk := theirsLines[t:tEnd]
+		switch {
+		case equalLines(oursChunk, baseChunk):
+			writeLineSlice(buf, theirsChunk, false)
+		case equalLines(theirsChunk, baseChunk), equalLines(oursChunk, theirsChunk):
+			writeLineSlice(buf, oursChunk, false)
+		default:
+			conflicts++
+			fmt.Fprintf(buf, "%s %s\n", conflictStart, labels.Ours)
+			writeLineSlice(buf, oursChunk, true)
+			buf.WriteString(conflictMiddle + "\n")
+			writeLineSlice(buf, theirsChunk, true)
+			fmt.Fprintf(buf, "%s %s\n", conflictEnd, labels.Theirs)
+		}
+		if end == len(baseLines) {
+			break
+		}
+		i, o, t = end, oEnd, tEnd
+	}
+	return buf.Bytes(), conflicts
+}
+
+// matchLines maps each base line to the line it's equal to in the other version,
+// or -1 if it was deleted or changed.
+func matchLines(script []DiffLine, n int) []int {
+	match := make([]int, n)
+	i, j := 0, 0
+	for _, l := range script {
+		switch l.Op {
+		case DiffEqual:
+			match[i] = j
+			i, j = i+1, j+1
+		case DiffDelete:
+			match[i] = -1
+			i++
+		case DiffInsert:
+			j++
+		}
+	}
+	return match
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeLineSlice writes lines. If a marker follows, the last is ended with a
+// newline so the marker starts a line.
+func writeLineSlice(buf *bytes.Buffer, lines []string, markerFollows bool) {
+	for _, l := range lines {
+		buf.WriteString(l)
+	}
+	if markerFollows && len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		buf.WriteByte('\n')
+	}
+}
+
+// HasConflictMarkers returns true if data contains a line starting with a conflict
+// marker, followed by a space or the end of the line.
+func HasConflictMarkers(data []byte) bool {
+	for _, line := range splitLines(data) {
+		line = strings.TrimRight(line, "\r\n")
+		for _, marker := range []string{conflictStart, conflictMiddle, conflictEnd} {
+			if line == marker || strings.HasPrefix(line, marker+" ") {
+				return true
+			}
+		}
+	}
+	return false
+}
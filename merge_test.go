@@ -0,0 +1,151 @@
+package editor
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          string
+		ours          string
+		theirs        string
+		want          string
+		wantConflicts int
+	}{
+		{
+			name:   "unchanged",
+			base:   "a\nb\n",
+			ours:   "a\nb\n",
+			theirs: "a\nb\n",
+			want:   "a\nb\n",
+		},
+		{
+			name:   "only ours changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "missing newline at end kept",
+			base:   "a\n",
+			ours:   "a\nb",
+			theirs: "a\n",
+			want:   "a\nb",
+		},
+		{
+			name:   "changes to different lines",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:   "same change on both sides",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "insertions and deletions",
+			base:   "a\nb\nc\nd\n",
+			ours:   "a\nnew\nb\nc\nd\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nnew\nb\nc\n",
+		},
+		{
+			name:          "conflicting changes",
+			base:          "a\nb\nc\n",
+			ours:          "a\nours\nc\n",
+			theirs:        "a\ntheirs\nc\n",
+			want:          "a\n<<<<<<< edited\nours\n=======\ntheirs\n>>>>>>> latest\nc\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "conflicting insertions at the end",
+			base:          "a\n",
+			ours:          "a\nours",
+			theirs:        "a\ntheirs\n",
+			want:          "a\n<<<<<<< edited\nours\n=======\ntheirs\n>>>>>>> latest\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "conflict and merge",
+			base:          "a\nb\nc\nd\ne\n",
+			ours:          "a\nours\nc\nd\ne\nf\n",
+			theirs:        "a\ntheirs\nc\nd\nE\n",
+			want:          "a\n<<<<<<< edited\nours\n=======\ntheirs\n>>>>>>> latest\nc\nd\n<<<<<<< edited\ne\nf\n=======\nE\n>>>>>>> latest\n",
+			wantConflicts: 2,
+		},
+		{
+			name:   "changed while other side deleted elsewhere",
+			base:   "a\nb\nc\nd\n",
+			ours:   "a\nb\nC\nd\n",
+			theirs: "b\nc\nd\n",
+			want:   "b\nC\nd\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge3([]byte(tt.base), []byte(tt.ours), []byte(tt.theirs), MergeLabels{Ours: "edited", Theirs: "latest"})
+			if string(got) != tt.want {
+				t.Errorf("Merge3() = %q, want %q", got, tt.want)
+			}
+			if conflicts != tt.wantConflicts {
+				t.Errorf("Merge3() conflicts = %d, want %d", conflicts, tt.wantConflicts)
+			}
+			if HasConflictMarkers(got) != (tt.wantConflicts > 0) {
+				t.Errorf("HasConflictMarkers() = %v, want %v", !(tt.wantConflicts > 0), tt.wantConflicts > 0)
+			}
+		})
+	}
+}
+
+func TestHasConflictMarkers(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{data: "a\n<<<<<<< ours\n", want: true},
+		{data: "=======\r\n", want: true},
+		{data: ">>>>>>>", want: true},
+		{data: "a <<<<<<< b\n", want: false},
+		{data: "========\n", want: false},
+	}
+	for _, tt := range tests {
+		if got := HasConflictMarkers([]byte(tt.data)); got != tt.want {
+			t.Errorf("HasConflictMarkers(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestValidatingEditor_RejectConflictMarkers(t *testing.T) {
+	e := NewValidatingEditor(&alwaysValidSchema{})
+	e.ErrorHeader = true
+	e.RejectConflictMarkers = true
+	edits := []string{"<<<<<<< ours\na\n=======\nb\n>>>>>>> theirs\n", "a\n"}
+	var shown []string
+	e.LaunchFn = func(command, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		shown = append(shown, string(data))
+		return os.WriteFile(file, []byte(edits[len(shown)-1]), 0600)
+	}
+	data, file, err := e.LaunchTempFile("prefix", bytes.NewBufferString("original\n"))
+	defer os.Remove(file)
+	if err != nil {
+		t.Fatalf("ValidatingEditor.LaunchTempFile() error = %v", err)
+	}
+	if string(data) != "a\n" || len(shown) != 2 {
+		t.Errorf("ValidatingEditor.LaunchTempFile() data = %q after %d launches, want %q after 2", data, len(shown), "a\n")
+	}
+	if want := "# " + ErrConflictMarkers.Error() + "\n"; !bytes.Contains([]byte(shown[1]), []byte(want)) {
+		t.Errorf("ValidatingEditor.LaunchTempFile() reopened with %q, want it to contain %q", shown[1], want)
+	}
+}
@@ -0,0 +1,124 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// MessageID identifies a user-facing message of the library.
+type MessageID string
+
+// Messages shown to the user. Messages ending in "Fmt" are format strings.
+const (
+	MsgValidationFailed        MessageID = "validation_failed"
+	MsgCancelledNoValidChanges MessageID = "cancelled_no_valid_changes"
+	MsgCancelledNoOrigChanges  MessageID = "cancelled_no_orig_changes"
+	MsgCancelledEmptyFile      MessageID = "cancelled_empty_file"
+	MsgCancelledDiscarded      MessageID = "cancelled_discarded"
+	MsgPreserveFileLocationFmt MessageID = "preserve_file_location"
+	MsgResourceConflict        MessageID = "resource_conflict"
+	MsgResolveConflicts        MessageID = "resolve_conflicts"
+	MsgReviewMerge             MessageID = "review_merge"
+	MsgConfirmPrompt           MessageID = "confirm_prompt"
+	MsgConfirmApply            MessageID = "confirm_apply"
+	MsgConfirmEditAgain        MessageID = "confirm_edit_again"
+	MsgConfirmDiscard          MessageID = "confirm_discard"
+	MsgDocumentFmt             MessageID = "document"
+	MsgDocumentInvalidFmt      MessageID = "document_invalid"
+	MsgListCommands            MessageID = "list_commands"
+	MsgListOrder               MessageID = "list_order"
+	MsgListRemove              MessageID = "list_remove"
+	MsgNamesHelp               MessageID = "names_help"
+)
+
+// Catalog maps message IDs to their text in one language. Messages missing
+// from a catalog are shown in English.
+type Catalog map[MessageID]string
+
+// English is the catalog of the library's original messages.
+var English = Catalog{
+	MsgValidationFailed:        msgValidationFailed,
+	MsgCancelledNoValidChanges: msgCancelledNoValidChanges,
+	MsgCancelledNoOrigChanges:  msgCancelledNoOrigChanges,
+	MsgCancelledEmptyFile:      msgCancelledEmptyFile,
+	MsgCancelledDiscarded:      msgCancelledDiscarded,
+	MsgPreserveFileLocationFmt: msgPreserveFileLocation,
+	MsgResourceConflict:        msgResourceConflict,
+	MsgResolveConflicts:        msgResolveConflicts,
+	MsgReviewMerge:             msgReviewMerge,
+	MsgConfirmPrompt:           msgConfirmPrompt,
+	MsgConfirmApply:            msgConfirmOptions[ConfirmApply],
+	MsgConfirmEditAgain:        msgConfirmOptions[ConfirmEditAgain],
+	MsgConfirmDiscard:          msgConfirmOptions[ConfirmDiscard],
+	MsgDocumentFmt:             msgDocument,
+	MsgDocumentInvalidFmt:      msgDocumentInvalid,
+	MsgListCommands:            msgListCommands,
+	MsgListOrder:               msgListOrder,
+	MsgListRemove:              msgListRemove,
+	MsgNamesHelp:               msgNamesHelp,
+}
+
+var (
+	catalogsMu sync.RWMutex
+	catalogs   = map[string]Catalog{"en": English}
+)
+
+// Get returns the text of a message, falling back to English.
+func (c Catalog) Get(id MessageID) string {
+	if s, ok := c[id]; ok {
+		return s
+	}
+	if s, ok := English[id]; ok {
+		return s
+	}
+	return string(id)
+}
+
+// Format returns the text of a format message with the given arguments.
+func (c Catalog) Format(id MessageID, args ...any) string {
+	return fmt.Sprintf(c.Get(id), args...)
+}
+
+// RegisterCatalog makes a catalog available for a locale, like "de" or "ja_JP".
+func RegisterCatalog(locale string, c Catalog) {
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+	catalogs[normalizeLocale(locale)] = c
+}
+
+// CatalogForLocale returns the catalog registered for a locale, like "de_DE.UTF-8",
+// or else for its language, like "de". It returns English if neither is registered.
+func CatalogForLocale(locale string) Catalog {
+	catalogsMu.RLock()
+	defer catalogsMu.RUnlock()
+	locale = normalizeLocale(locale)
+	if c, ok := catalogs[locale]; ok {
+		return c
+	}
+	lang, _, _ := strings.Cut(locale, "_")
+	if c, ok := catalogs[lang]; ok {
+		return c
+	}
+	return English
+}
+
+// LocaleFromEnv returns the locale for messages from the LC_ALL, LC_MESSAGES or
+// LANG environment variables, in that order.
+func LocaleFromEnv() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// normalizeLocale turns "de-DE.UTF-8@euro" into "de_de".
+func normalizeLocale(locale string) string {
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	return strings.ToLower(strings.ReplaceAll(locale, "-", "_"))
+}
@@ -0,0 +1,95 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCatalog_Get(t *testing.T) {
+	tests := []struct {
+		name    string
+		catalog Catalog
+		id      MessageID
+		want    string
+	}{
+		{name: "translated", catalog: Catalog{MsgConfirmPrompt: "Änderungen übernehmen?"}, id: MsgConfirmPrompt, want: "Änderungen übernehmen?"},
+		{name: "missing falls back to english", catalog: Catalog{}, id: MsgConfirmPrompt, want: msgConfirmPrompt},
+		{name: "nil catalog", id: MsgCancelledEmptyFile, want: msgCancelledEmptyFile},
+		{name: "unknown id", id: "unknown", want: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.catalog.Get(tt.id); got != tt.want {
+				t.Errorf("Get() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalogForLocale(t *testing.T) {
+	german := Catalog{MsgConfirmPrompt: "Änderungen übernehmen?"}
+	swiss := Catalog{MsgConfirmPrompt: "Änderige übernäh?"}
+	RegisterCatalog("de", german)
+	RegisterCatalog("de_CH", swiss)
+	defer func() {
+		catalogsMu.Lock()
+		delete(catalogs, "de")
+		delete(catalogs, "de_ch")
+		catalogsMu.Unlock()
+	}()
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{locale: "de", want: german[MsgConfirmPrompt]},
+		{locale: "de_DE.UTF-8", want: german[MsgConfirmPrompt]},
+		{locale: "de_CH.UTF-8@euro", want: swiss[MsgConfirmPrompt]},
+		{locale: "de-ch", want: swiss[MsgConfirmPrompt]},
+		{locale: "fr_FR.UTF-8", want: msgConfirmPrompt},
+		{locale: "C", want: msgConfirmPrompt},
+		{locale: "", want: msgConfirmPrompt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			if got := CatalogForLocale(tt.locale).Get(MsgConfirmPrompt); got != tt.want {
+				t.Errorf("CatalogForLocale(%q) = %q, want %q", tt.locale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocaleFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "lang", env: map[string]string{"LANG": "de_DE.UTF-8"}, want: "de_DE.UTF-8"},
+		{name: "lc_messages over lang", env: map[string]string{"LANG": "de_DE.UTF-8", "LC_MESSAGES": "fr_FR"}, want: "fr_FR"},
+		{name: "lc_all over all", env: map[string]string{"LANG": "de_DE", "LC_MESSAGES": "fr_FR", "LC_ALL": "ja_JP"}, want: "ja_JP"},
+		{name: "unset", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if got := LocaleFromEnv(); got != tt.want {
+				t.Errorf("LocaleFromEnv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatingEditor_Messages(t *testing.T) {
+	edit := NewValidatingEditor(nil)
+	edit.Reporter = NopReporter{}
+	edit.Messages = Catalog{MsgCancelledNoOrigChanges: "Keine Änderungen"}
+	edit.LaunchFn = func(command, file string) error { return nil }
+
+	_, _, err := edit.LaunchTempFile("test", strings.NewReader("unchanged"))
+	if err == nil || err.Error() != "Keine Änderungen" {
+		t.Errorf("Launch() error = %v, want %q", err, "Keine Änderungen")
+	}
+}
@@ -0,0 +1,194 @@
+package editor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var msgNamesHelp = "Edit the names after the ids, or remove a line to delete its item."
+
+// Rename changes the name of an item.
+type Rename struct {
+	Old string
+	New string
+}
+
+// RenamePlan is the outcome of EditNames. Apply the Deletions first, and then
+// the Renames in order, so no name is taken when it is renamed to.
+type RenamePlan struct {
+	Renames   []Rename
+	Deletions []string
+}
+
+// EditNames lets the user rename items, like files with vidir, by editing their
+// names. Each name is shown on a line after a stable id, and removing a line
+// deletes its item. Leading and trailing whitespace of names is ignored.
+//
+// The edited names are validated before the plan is returned, reopening the
+// editor on errors like LaunchTempFile, with the errors always in a header. A
+// name can't be empty, given to more than one item, or taken by an item which
+// isn't renamed or deleted. Renames forming a cycle, like swapping two names, go
+// through a temporary name which isn't taken, like "alpha.tmp". The edited
+// names are checked for being unchanged or empty like LaunchTempFile, and the
+// Schema isn't used.
+func (e *ValidatingEditor) EditNames(ctx context.Context, prefix string, names []string) (*RenamePlan, error) {
+	seen := map[string]bool{}
+	for _, name := range names {
+		if name == "" || name != strings.TrimSpace(name) || strings.Contains(name, "\n") {
+			return nil, fmt.Errorf("invalid name %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate name %q", name)
+		}
+		seen[name] = true
+	}
+
+	original := e.renderNames(names)
+	edit := *e
+	edit.Schema = namesSchema{names}
+	edit.CommentSyntax = &listComments
+	edit.ErrorHeader = true
+	edit.StripComments = false
+	edit.JSONC = false
+	edit.EmptyPredicate = nil
+	res, err := edit.launchTempFile(ctx, prefix, original, original, nil)
+	if err != nil {
+		return nil, err
+	}
+	if res.Path != "" {
+		os.Remove(res.Path)
+	}
+	return planRenames(names, res.Data)
+}
+
+// renderNames writes the names after their ids, followed by a help comment.
+func (e *ValidatingEditor) renderNames(names []string) []byte {
+	buf := &bytes.Buffer{}
+	width := len(strconv.Itoa(len(names)))
+	for i, name := range names {
+		fmt.Fprintf(buf, "%0*d\t%s\n", width, i+1, name)
+	}
+	fmt.Fprintf(buf, "\n# %s\n", e.Messages.Get(MsgNamesHelp))
+	return buf.Bytes()
+}
+
+// namesSchema validates edited names against the original names.
+type namesSchema struct {
+	names []string
+}
+
+func (s namesSchema) ValidateBytes(data []byte) error {
+	_, err := planRenames(s.names, data)
+	return err
+}
+
+// planRenames parses edited "<id> <name>" lines, skipping blank lines and
+// comments, and plans the renames. Errors are PositionErrors of the lines, joined.
+func planRenames(names []string, data []byte) (*RenamePlan, error) {
+	var (
+		errs    []error
+		lineOf  = map[string]int{} // lines by original name
+		byName  = map[string]int{} // lines by edited name
+		renamed = map[string]string{}
+	)
+	for i, line := range splitLines(data) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fail := func(format string, args ...any) {
+			errs = append(errs, &PositionError{Line: i + 1, Err: fmt.Errorf(format, args...)})
+		}
+		id, name := line, ""
+		if j := strings.IndexFunc(line, unicode.IsSpace); j >= 0 {
+			id, name = line[:j], strings.TrimSpace(line[j:])
+		}
+		n, err := strconv.Atoi(id)
+		if err != nil || n < 1 || n > len(names) {
+			fail("unknown id %q", id)
+			continue
+		}
+		old := names[n-1]
+		switch {
+		case lineOf[old] != 0:
+			fail("duplicate id %q, also on line %d", id, lineOf[old])
+			continue
+		case name == "":
+			fail("empty name for %q", old)
+			continue
+		case byName[name] != 0:
+			fail("name %q is also given on line %d", name, byName[name])
+			continue
+		}
+		lineOf[old], byName[name] = i+1, i+1
+		if name != old {
+			renamed[old] = name
+		}
+	}
+
+	plan := &RenamePlan{}
+	for _, name := range names {
+		if lineOf[name] == 0 {
+			plan.Deletions = append(plan.Deletions, name)
+		}
+	}
+
+	// order the renames so each name is vacated before it's taken, breaking
+	// cycles by first moving one of their names out of the way
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	temps := map[string]string{}
+	var visit func(old string)
+	visit = func(old string) {
+		state[old] = visiting
+		next := renamed[old]
+		if _, moving := renamed[next]; moving {
+			switch state[next] {
+			case visiting:
+				temps[next] = tempName(next, names, byName, temps)
+				plan.Renames = append(plan.Renames, Rename{Old: next, New: temps[next]})
+			case 0:
+				visit(next)
+			}
+		}
+		state[old] = visited
+		if temp, ok := temps[old]; ok {
+			plan.Renames = append(plan.Renames, Rename{Old: temp, New: next})
+		} else {
+			plan.Renames = append(plan.Renames, Rename{Old: old, New: next})
+		}
+	}
+	for _, name := range names {
+		if _, ok := renamed[name]; ok && state[name] == 0 {
+			visit(name)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// tempName returns a name for moving name out of the way, like "name.tmp", which
+// is neither an original nor an edited name, nor used for another name.
+func tempName(name string, names []string, edited map[string]int, temps map[string]string) string {
+	used := func(temp string) bool {
+		return slices.Contains(names, temp) || edited[temp] != 0 || slices.Contains(slices.Collect(maps.Values(temps)), temp)
+	}
+	temp := name + ".tmp"
+	for i := 2; used(temp); i++ {
+		temp = fmt.Sprintf("%s.tmp%d", name, i)
+	}
+	return temp
+}
@@ -0,0 +1,141 @@
+package editor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_planRenames(t *testing.T) {
+	names := []string{"alpha", "beta", "gamma", "delta"}
+	tests := []struct {
+		name    string
+		data    string
+		want    *RenamePlan
+		wantErr string
+	}{
+		{
+			name: "unchanged",
+			data: "1\talpha\n2\tbeta\n3\tgamma\n4\tdelta\n\n# help\n",
+			want: &RenamePlan{},
+		},
+		{
+			name: "rename and delete",
+			data: "2  beta two \n1\talpha one\n4\tdelta\n",
+			want: &RenamePlan{
+				Renames:   []Rename{{Old: "alpha", New: "alpha one"}, {Old: "beta", New: "beta two"}},
+				Deletions: []string{"gamma"},
+			},
+		},
+		{
+			name: "chain",
+			data: "1\tbeta\n2\tgamma\n4\tdelta\n",
+			want: &RenamePlan{
+				Renames:   []Rename{{Old: "beta", New: "gamma"}, {Old: "alpha", New: "beta"}},
+				Deletions: []string{"gamma"},
+			},
+		},
+		{
+			name: "cycle",
+			data: "1\tbeta\n2\tgamma\n3\talpha\n4\tdelta\n",
+			want: &RenamePlan{Renames: []Rename{
+				{Old: "alpha", New: "alpha.tmp"},
+				{Old: "gamma", New: "alpha"},
+				{Old: "beta", New: "gamma"},
+				{Old: "alpha.tmp", New: "beta"},
+			}},
+		},
+		{
+			name: "swap with taken temporary name",
+			data: "1\tbeta\n2\talpha\n3\talpha.tmp\n",
+			want: &RenamePlan{
+				Renames: []Rename{
+					{Old: "alpha", New: "alpha.tmp2"},
+					{Old: "beta", New: "alpha"},
+					{Old: "alpha.tmp2", New: "beta"},
+					{Old: "gamma", New: "alpha.tmp"},
+				},
+				Deletions: []string{"delta"},
+			},
+		},
+		{
+			name:    "errors",
+			data:    "1\talpha\n1\tuno\n9\tnine\nx\n3\n2\tdelta\n4\tdelta\n",
+			wantErr: "line 2: duplicate id \"1\", also on line 1\nline 3: unknown id \"9\"\nline 4: unknown id \"x\"\nline 5: empty name for \"gamma\"\nline 7: name \"delta\" is also given on line 6",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := planRenames(names, []byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("planRenames() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("planRenames() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planRenames() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatingEditor_EditNames(t *testing.T) {
+	names := []string{"orders", "payments", "users", "logs", "audit", "events", "metrics", "traces", "alerts", "jobs"}
+	e := NewValidatingEditor(nil)
+	e.Reporter = NopReporter{}
+	var shown []string
+	edits := []string{
+		"01\tusers\n03\tusers\n",
+		"01\tpurchases\n03\tusers\n10\tjobs\n",
+	}
+	e.LaunchFn = func(command, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		shown = append(shown, string(data))
+		return os.WriteFile(file, []byte(edits[len(shown)-1]), 0600)
+	}
+
+	got, err := e.EditNames(context.Background(), "names", names)
+	if err != nil {
+		t.Fatalf("EditNames() error = %v", err)
+	}
+	want := &RenamePlan{
+		Renames:   []Rename{{Old: "orders", New: "purchases"}},
+		Deletions: []string{"payments", "logs", "audit", "events", "metrics", "traces", "alerts"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EditNames() = %+v, want %+v", got, want)
+	}
+	if len(shown) != 2 {
+		t.Fatalf("editor launched %d times, want 2", len(shown))
+	}
+	wantFirst := "01\torders\n02\tpayments\n03\tusers\n04\tlogs\n05\taudit\n06\tevents\n07\tmetrics\n08\ttraces\n09\talerts\n10\tjobs\n\n" +
+		"# Edit the names after the ids, or remove a line to delete its item.\n"
+	if shown[0] != wantFirst {
+		t.Errorf("first shown %q, want %q", shown[0], wantFirst)
+	}
+	if !bytes.Contains([]byte(shown[1]), []byte(`line 5: name "users" is also given on line 1`)) {
+		t.Errorf("second shown %q, want the validation error", shown[1])
+	}
+}
+
+func TestValidatingEditor_EditNames_InvalidNames(t *testing.T) {
+	for _, names := range [][]string{{"a", "a"}, {""}, {" a"}, {"a\nb"}} {
+		e := NewValidatingEditor(nil)
+		e.LaunchFn = func(command, file string) error {
+			t.Errorf("editor launched for %q", names)
+			return nil
+		}
+		if _, err := e.EditNames(context.Background(), "names", names); err == nil {
+			t.Errorf("EditNames(%q) succeeded", names)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package editor
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// EditorProfile describes what an editor can do beyond editing a single file.
+type EditorProfile struct {
+	// Names are the names of the editor's executables, like "vim" and "nvim".
+	Names []string
+	// MultiFile editors open several files given as arguments in one launch.
+	MultiFile bool
+	// MultiFileArgs precede the files in a multi-file launch, like "-p" to open them in tabs in vim.
+	MultiFileArgs []string
+	// Split editors show two files given as arguments side by side.
+	Split bool
+	// SplitArgs precede the files in a split launch, like "-O" in vim.
+	SplitArgs []string
+}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string]EditorProfile{}
+)
+
+func init() {
+	for _, p := range []EditorProfile{
+		{
+			Names:     []string{"vim", "nvim", "vi", "gvim", "mvim"},
+			MultiFile: true, MultiFileArgs: []string{"-p"},
+			// focus the right window, which is the file being edited
+			Split: true, SplitArgs: []string{"-O", "-c", "wincmd l"},
+		},
+		{Names: []string{"code", "code-insiders", "codium", "cursor"}, MultiFile: true, Split: true},
+		{Names: []string{"emacs", "emacsclient"}, MultiFile: true, Split: true},
+		{Names: []string{"subl", "mate", "hx", "kak", "micro", "nano"}, MultiFile: true},
+	} {
+		RegisterProfile(p)
+	}
+}
+
+// RegisterProfile makes a profile available for its editor names, replacing any
+// registered before.
+func RegisterProfile(p EditorProfile) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	for _, name := range p.Names {
+		profiles[name] = p
+	}
+}
+
+// ProfileFor returns the profile of the editor started by command, like
+// "code --wait", by the name of its executable. Editors without a profile can
+// only edit one file at a time.
+func ProfileFor(command string) EditorProfile {
+	args := splitCommand(command)
+	if len(args) == 0 {
+		return EditorProfile{}
+	}
+	// accept Windows paths on any platform, as EDITOR may be shared between them
+	name := args[0][strings.LastIndexAny(args[0], `/\`)+1:]
+	name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	if p, ok := profiles[name]; ok {
+		return p
+	}
+	return EditorProfile{Names: []string{name}}
+}
+
+// profile returns Profile, or the profile for Command.
+func (e *BasicEditor) profile() EditorProfile {
+	if e.Profile != nil {
+		return *e.Profile
+	}
+	return ProfileFor(e.Command)
+}
+
+// LaunchFiles opens the given file paths in one launch of the external editor,
+// if its profile allows, or else one after another.
+func (e *BasicEditor) LaunchFiles(files ...string) error {
+	return e.LaunchFilesContext(context.Background(), files...)
+}
+
+// LaunchFilesContext is like LaunchFiles but returns the context's error instead
+// of launching the editor once the context is done.
+func (e *BasicEditor) LaunchFilesContext(ctx context.Context, files ...string) error {
+	p := e.profile()
+	if !p.MultiFile || len(files) < 2 {
+		for _, file := range files {
+			if err := e.LaunchContext(ctx, file); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return e.launchWith(ctx, p.MultiFileArgs, files)
+}
+
+// launchWith launches the editor with extra arguments and files. The last file
+// is passed to LaunchFn as the file, the others are appended to the command.
+func (e *BasicEditor) launchWith(ctx context.Context, args, files []string) error {
+	cmd := e.clone()
+	var words []string
+	for _, arg := range append(append(splitCommand(e.Command), args...), files[:len(files)-1]...) {
+		words = append(words, quoteArg(arg))
+	}
+	cmd.Command = strings.Join(words, " ")
+	return cmd.LaunchContext(ctx, files[len(files)-1])
+}
+
+// quoteArg quotes an argument for splitCommand, if needed.
+func quoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
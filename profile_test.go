@@ -0,0 +1,98 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProfileFor(t *testing.T) {
+	tests := []struct {
+		command   string
+		multiFile bool
+		args      []string
+	}{
+		{command: "vim", multiFile: true, args: []string{"-p"}},
+		{command: "/usr/bin/nvim -u NONE", multiFile: true, args: []string{"-p"}},
+		{command: "code --wait", multiFile: true},
+		{command: `"C:\Program Files\Microsoft VS Code\Code.exe" --wait`, multiFile: true},
+		{command: "ed"},
+		{command: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			p := ProfileFor(tt.command)
+			if p.MultiFile != tt.multiFile || !reflect.DeepEqual(p.MultiFileArgs, tt.args) {
+				t.Errorf("ProfileFor() = %+v, want multi-file %v with %q", p, tt.multiFile, tt.args)
+			}
+		})
+	}
+}
+
+func TestBasicEditor_LaunchFiles(t *testing.T) {
+	type launch struct{ command, file string }
+	tests := []struct {
+		name    string
+		command string
+		profile *EditorProfile
+		files   []string
+		want    []launch
+	}{
+		{
+			name:    "vim tabs",
+			command: "vim",
+			files:   []string{"a.yaml", "notes dir/b.md", "c.txt"},
+			want:    []launch{{command: `vim -p a.yaml "notes dir/b.md"`, file: "c.txt"}},
+		},
+		{
+			name:    "code with arguments",
+			command: "code --wait",
+			files:   []string{"a.yaml", "b.md"},
+			want:    []launch{{command: "code --wait a.yaml", file: "b.md"}},
+		},
+		{
+			name:    "sequential",
+			command: "ed",
+			files:   []string{"a.yaml", "b.md"},
+			want:    []launch{{command: "ed", file: "a.yaml"}, {command: "ed", file: "b.md"}},
+		},
+		{
+			name:    "single file",
+			command: "vim",
+			files:   []string{"a.yaml"},
+			want:    []launch{{command: "vim", file: "a.yaml"}},
+		},
+		{
+			name:    "profile",
+			command: "my-editor",
+			profile: &EditorProfile{MultiFile: true, MultiFileArgs: []string{"--tabs"}},
+			files:   []string{"a.yaml", "b.md"},
+			want:    []launch{{command: "my-editor --tabs a.yaml", file: "b.md"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []launch
+			e := NewEditor()
+			e.Command = tt.command
+			e.Profile = tt.profile
+			e.LaunchFn = func(command, file string) error {
+				got = append(got, launch{command, file})
+				return nil
+			}
+			if err := e.LaunchFiles(tt.files...); err != nil {
+				t.Fatalf("LaunchFiles() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LaunchFiles() launched %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_quoteArg(t *testing.T) {
+	for _, arg := range []string{"plain", "", "with space", `C:\dir\file`, `say "hi"`, "it's"} {
+		if got := splitCommand("editor " + quoteArg(arg)); !reflect.DeepEqual(got, []string{"editor", arg}) {
+			t.Errorf("splitCommand(quoteArg(%q)) = %q", arg, got)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package editor
+
+import (
+	"context"
+	"os"
+)
+
+// Reference is read-only data to show next to the edited data, like the
+// original, the current version on a server or documentation.
+type Reference struct {
+	// Pattern names the temporary file, as in os.CreateTemp, like "original-*.yaml".
+	// Defaults to "reference-*".
+	Pattern string
+	Data    []byte
+}
+
+// launchReference opens file, and the Reference beside it if the editor can
+// split. The reference file is removed again once the editor exits.
+func (e *BasicEditor) launchReference(ctx context.Context, file string) error {
+	p := e.profile()
+	if e.Reference == nil || !p.Split {
+		return e.LaunchContext(ctx, file)
+	}
+	ref, err := writeReference(e.Reference)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(ref)
+	return e.launchWith(ctx, p.SplitArgs, []string{ref, file})
+}
+
+// writeReference writes a read-only temp file with the reference data.
+func writeReference(r *Reference) (string, error) {
+	pattern := r.Pattern
+	if pattern == "" {
+		pattern = "reference-*"
+	}
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(r.Data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0400)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,100 @@
+package editor
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestBasicEditor_Reference(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		wantArgs []string // before the reference file
+	}{
+		{name: "vim", command: "vim", wantArgs: []string{"vim", "-O", "-c", "wincmd l"}},
+		{name: "code", command: "code --wait", wantArgs: []string{"code", "--wait"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ref string
+			e := NewEditor()
+			e.Command = tt.command
+			e.Reference = &Reference{Pattern: "original-*.yaml", Data: []byte("name: old\n")}
+			e.LaunchFn = func(command, file string) error {
+				args := splitCommand(command)
+				if got := args[:len(args)-1]; !reflect.DeepEqual(got, tt.wantArgs) {
+					t.Errorf("launched %q, want %q before the reference", got, tt.wantArgs)
+				}
+				ref = args[len(args)-1]
+				if data, err := os.ReadFile(ref); err != nil || string(data) != "name: old\n" {
+					t.Errorf("reference = %q, %v", data, err)
+				}
+				if info, err := os.Stat(ref); err != nil || info.Mode().Perm()&0200 != 0 {
+					t.Errorf("reference is writable: %v", err)
+				}
+				return os.WriteFile(file, []byte("name: new\n"), 0600)
+			}
+
+			data, file, err := e.LaunchTempFile("example-*.yaml", bytes.NewBufferString("name: old\n"))
+			defer os.Remove(file)
+			if err != nil {
+				t.Fatalf("LaunchTempFile() error = %v", err)
+			}
+			if string(data) != "name: new\n" {
+				t.Errorf("LaunchTempFile() data = %q", data)
+			}
+			if _, err := os.Stat(ref); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("reference %q wasn't removed: %v", ref, err)
+			}
+		})
+	}
+}
+
+func TestBasicEditor_Reference_NoSplit(t *testing.T) {
+	e := NewEditor()
+	e.Command = "ed"
+	e.Reference = &Reference{Data: []byte("reference\n")}
+	e.LaunchFn = func(command, file string) error {
+		if command != "ed" {
+			t.Errorf("launched %q without a split, want ed", command)
+		}
+		return nil
+	}
+	_, file, err := e.LaunchTempFile("example", bytes.NewBufferString("data\n"))
+	defer os.Remove(file)
+	if err != nil {
+		t.Fatalf("LaunchTempFile() error = %v", err)
+	}
+}
+
+func TestValidatingEditor_Reference(t *testing.T) {
+	e := NewValidatingEditor(&prefixSchema{prefix: "valid"})
+	e.Reporter = NopReporter{}
+	e.Command = "vim"
+	e.Reference = &Reference{Data: []byte("invalid reference\n")}
+	var attempts int
+	e.LaunchFn = func(command, file string) error {
+		attempts++
+		args := splitCommand(command)
+		if ref, err := os.ReadFile(args[len(args)-1]); err != nil || string(ref) != "invalid reference\n" {
+			t.Errorf("attempt %d: reference = %q, %v", attempts, ref, err)
+		}
+		data := "invalid\n"
+		if attempts > 1 {
+			data = "valid\n"
+		}
+		return os.WriteFile(file, []byte(data), 0600)
+	}
+
+	data, file, err := e.LaunchTempFile("example", bytes.NewBufferString("original\n"))
+	defer os.Remove(file)
+	if err != nil {
+		t.Fatalf("LaunchTempFile() error = %v", err)
+	}
+	if string(data) != "valid\n" || attempts != 2 {
+		t.Errorf("LaunchTempFile() = %q after %d attempts, want valid after 2", data, attempts)
+	}
+}
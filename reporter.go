@@ -0,0 +1,150 @@
+package editor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrNoPrompt is returned by Reporters which cannot ask the user anything.
+var ErrNoPrompt = errors.New("cannot prompt for input")
+
+// Reporter shows messages to the user and asks them questions. Implement it to
+// route the editor's output through your own logger or UI.
+type Reporter interface {
+	Info(msg string)
+	Warn(msg string)
+	Error(msg string)
+	// Confirm asks a yes or no question.
+	Confirm(question string) (bool, error)
+	// Prompt asks the user to choose one of the options and returns its index.
+	Prompt(question string, options []string) (int, error)
+}
+
+// TextReporter is a Reporter which writes plain text lines and reads answers line by line.
+type TextReporter struct {
+	in  *bufio.Reader
+	out io.Writer
+	err io.Writer
+}
+
+// NewTextReporter returns a TextReporter which writes info messages and questions
+// to out, warnings and errors to errOut and reads answers from in.
+func NewTextReporter(in io.Reader, out, errOut io.Writer) *TextReporter {
+	return &TextReporter{in: bufio.NewReader(in), out: out, err: errOut}
+}
+
+// newDefaultReporter returns the reporter of NewValidatingEditor, which writes
+// everything to stdout.
+func newDefaultReporter() Reporter {
+	return NewTextReporter(os.Stdin, os.Stdout, os.Stdout)
+}
+
+// Info writes msg to out.
+func (r *TextReporter) Info(msg string) {
+	fmt.Fprintln(r.out, msg)
+}
+
+// Warn writes msg to errOut.
+func (r *TextReporter) Warn(msg string) {
+	fmt.Fprintln(r.err, msg)
+}
+
+// Error writes msg to errOut.
+func (r *TextReporter) Error(msg string) {
+	fmt.Fprintln(r.err, msg)
+}
+
+// Confirm asks until the answer is yes or no. An empty answer is no.
+func (r *TextReporter) Confirm(question string) (bool, error) {
+	for {
+		fmt.Fprintf(r.out, "%s [y/N]: ", question)
+		answer, err := r.readLine()
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			return true, nil
+		case "", "n", "no":
+			if err == nil || answer != "" {
+				return false, nil
+			}
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// Prompt asks until the answer is one of the options, its first letter or its
+// 1-based number. Options should start with different letters.
+func (r *TextReporter) Prompt(question string, options []string) (int, error) {
+	var choices []string
+	for _, o := range options {
+		if short := shortcut(o); short != "" {
+			choices = append(choices, "["+short+"]"+o[len(short):])
+		}
+	}
+	for {
+		fmt.Fprintf(r.out, "%s %s: ", question, strings.Join(choices, ", "))
+		answer, err := r.readLine()
+		if i, ok := parseChoice(strings.ToLower(answer), options); ok {
+			return i, nil
+		}
+		if err != nil {
+			return -1, err
+		}
+	}
+}
+
+// readLine reads an answer, ending the prompt's line at the end of input.
+func (r *TextReporter) readLine() (string, error) {
+	line, err := r.in.ReadString('\n')
+	if err == io.EOF {
+		fmt.Fprintln(r.out)
+	}
+	return strings.TrimSpace(line), err
+}
+
+func parseChoice(answer string, options []string) (int, bool) {
+	if answer == "" {
+		return -1, false
+	}
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		return n - 1, true
+	}
+	for i, o := range options {
+		if o = strings.ToLower(o); o != "" && (answer == o || answer == shortcut(o)) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// shortcut returns the first character of an option, which may be typed instead of it.
+func shortcut(option string) string {
+	_, size := utf8.DecodeRuneInString(option)
+	return option[:size]
+}
+
+// NopReporter is a Reporter which shows nothing and cannot ask questions,
+// for using the editor as a library.
+type NopReporter struct{}
+
+// Info does nothing.
+func (NopReporter) Info(string) {}
+
+// Warn does nothing.
+func (NopReporter) Warn(string) {}
+
+// Error does nothing.
+func (NopReporter) Error(string) {}
+
+// Confirm returns ErrNoPrompt.
+func (NopReporter) Confirm(string) (bool, error) { return false, ErrNoPrompt }
+
+// Prompt returns ErrNoPrompt.
+func (NopReporter) Prompt(string, []string) (int, error) { return -1, ErrNoPrompt }
@@ -0,0 +1,127 @@
+package editor
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTextReporter(t *testing.T) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	r := NewTextReporter(strings.NewReader(""), out, errOut)
+	r.Info("info")
+	r.Warn("warn")
+	r.Error("error")
+	if out.String() != "info\n" {
+		t.Errorf("TextReporter out = %q, want %q", out.String(), "info\n")
+	}
+	if errOut.String() != "warn\nerror\n" {
+		t.Errorf("TextReporter errOut = %q, want %q", errOut.String(), "warn\nerror\n")
+	}
+}
+
+func TestTextReporter_Confirm(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    bool
+		wantErr error
+	}{
+		{input: "y\n", want: true},
+		{input: "YES\n", want: true},
+		{input: "n\n", want: false},
+		{input: "\n", want: false},
+		{input: "maybe\ny\n", want: true},
+		{input: "", wantErr: io.EOF},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		got, err := NewTextReporter(strings.NewReader(tt.input), out, out).Confirm("Sure?")
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("TextReporter.Confirm(%q) = %v, %v, want %v, %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+		if !strings.HasPrefix(out.String(), "Sure? [y/N]: ") {
+			t.Errorf("TextReporter.Confirm(%q) prompt = %q", tt.input, out.String())
+		}
+	}
+}
+
+func TestTextReporter_Prompt(t *testing.T) {
+	options := []string{"apply", "edit again", "discard"}
+	tests := []struct {
+		input   string
+		want    int
+		wantErr error
+	}{
+		{input: "apply\n", want: 0},
+		{input: "e\n", want: 1},
+		{input: "3\n", want: 2},
+		{input: "4\nd", want: 2},
+		{input: "x\n", want: -1, wantErr: io.EOF},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		got, err := NewTextReporter(strings.NewReader(tt.input), out, out).Prompt("Apply?", options)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("TextReporter.Prompt(%q) = %v, %v, want %v, %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+		if !strings.HasPrefix(out.String(), "Apply? [a]pply, [e]dit again, [d]iscard: ") {
+			t.Errorf("TextReporter.Prompt(%q) prompt = %q", tt.input, out.String())
+		}
+	}
+}
+
+func TestTextReporter_PromptMultibyte(t *testing.T) {
+	options := []string{"適用", "", "再編集", "破棄"}
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{input: "適\n", want: 0},
+		{input: "再編集\n", want: 2},
+		{input: "\n破\n", want: 3},
+		{input: "2\n", want: 1},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		got, err := NewTextReporter(strings.NewReader(tt.input), out, out).Prompt("適用しますか?", options)
+		if got != tt.want || err != nil {
+			t.Errorf("TextReporter.Prompt(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+		}
+		if !strings.HasPrefix(out.String(), "適用しますか? [適]用, [再]編集, [破]棄: ") {
+			t.Errorf("TextReporter.Prompt(%q) prompt = %q", tt.input, out.String())
+		}
+	}
+}
+
+func TestValidatingEditor_Reporter(t *testing.T) {
+	out := &bytes.Buffer{}
+	e := NewValidatingEditor(&alwaysInvalidSchema{})
+	e.Reporter = NewTextReporter(strings.NewReader(""), out, out)
+	e.LaunchFn = func(command, file string) error {
+		return os.WriteFile(file, []byte("invalid data"), 0600)
+	}
+	_, file, err := e.LaunchTempFile("prefix", bytes.NewBufferString("original data"))
+	defer os.Remove(file)
+	if err == nil || err.Error() != msgCancelledNoValidChanges {
+		t.Errorf("ValidatingEditor.LaunchTempFile() error = %v, want %v", err, msgCancelledNoValidChanges)
+	}
+	want := msgValidationFailed + ": invalid\nA copy of your changes has been stored to " + file + "\n"
+	if out.String() != want {
+		t.Errorf("ValidatingEditor.LaunchTempFile() output = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	e = NewValidatingEditor(&alwaysInvalidSchema{})
+	e.Reporter = NopReporter{}
+	e.LaunchFn = func(command, file string) error {
+		return os.WriteFile(file, []byte("invalid data"), 0600)
+	}
+	_, file, _ = e.LaunchTempFile("prefix", bytes.NewBufferString("original data"))
+	defer os.Remove(file)
+	if out.Len() != 0 {
+		t.Errorf("ValidatingEditor.LaunchTempFile() wrote %q with NopReporter", out.String())
+	}
+}
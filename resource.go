@@ -0,0 +1,123 @@
+package editor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// ErrConflict is returned (possibly wrapped) by Resource.Update when the resource
+// was changed since the given version was read.
+var ErrConflict = errors.New("resource version conflict")
+
+// Resource is a versioned object which can be edited, such as one fetched from an API.
+type Resource interface {
+	// Get returns the current data and its version.
+	Get(ctx context.Context) (data []byte, version string, err error)
+	// Update replaces the data if the resource is still at the expected version,
+	// and otherwise returns an error wrapping ErrConflict.
+	Update(ctx context.Context, data []byte, version string) error
+}
+
+// EditResource lets the user edit a resource and updates it with optimistic
+// concurrency control. The edited data is validated like LaunchTempFile.
+//
+// If the resource was changed while the editor was open, the latest version is
+// fetched and the editor is reopened with the user's changes merged in with
+// Merge3. Conflicting changes are marked, and the edited data isn't accepted
+// until the markers are removed. The user is told why in the ErrorHeader, or
+// else with a warning from the Reporter.
+//
+// Returns the data the resource was updated with. If the update fails for any
+// other reason, the edited data is preserved with PreserveFileFn. When not
+// interactive, the update is attempted once.
+func (e *ValidatingEditor) EditResource(ctx context.Context, prefix string, r Resource) ([]byte, error) {
+	base, version, err := r.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	initial := base
+	var reason []string
+	for {
+		res, err := e.launchTempFile(ctx, prefix, base, initial, reason)
+		if err != nil {
+			return res.Data, err
+		}
+		edited, file := res.Data, res.Path
+
+		err = r.Update(ctx, edited, version)
+		if err == nil {
+			os.Remove(file)
+			return edited, nil
+		}
+		if !e.interactive() {
+			return nil, err
+		}
+		if !errors.Is(err, ErrConflict) {
+			data, _, err := e.preserve(ctx, edited, file, err)
+			return data, err
+		}
+		os.Remove(file)
+
+		latest, latestVersion, err := r.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(edited, latest) {
+			// someone else made the same changes
+			return edited, nil
+		}
+		merged, conflicts := Merge3(base, edited, latest, MergeLabels{Ours: "edited", Theirs: "latest"})
+		reason = []string{e.Messages.Get(MsgResourceConflict), e.Messages.Get(MsgReviewMerge)}
+		if conflicts > 0 {
+			reason = []string{e.Messages.Get(MsgResourceConflict), e.Messages.Get(MsgResolveConflicts)}
+			e = e.rejectingConflictMarkers()
+		}
+		base, version, initial = latest, latestVersion, merged
+	}
+}
+
+// MemoryResource is a Resource held in memory, useful for testing.
+type MemoryResource struct {
+	mu      sync.Mutex
+	data    []byte
+	version int
+}
+
+// NewMemoryResource returns a MemoryResource with the given data at version "1".
+func NewMemoryResource(data []byte) *MemoryResource {
+	return &MemoryResource{data: data, version: 1}
+}
+
+// Get returns the current data and version.
+func (r *MemoryResource) Get(context.Context) ([]byte, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return bytes.Clone(r.data), strconv.Itoa(r.version), nil
+}
+
+// Update replaces the data if the version matches, incrementing it.
+func (r *MemoryResource) Update(_ context.Context, data []byte, version string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if version != strconv.Itoa(r.version) {
+		return fmt.Errorf("expected version %s, found %d: %w", version, r.version, ErrConflict)
+	}
+	r.data = bytes.Clone(data)
+	r.version++
+	return nil
+}
+
+// Set replaces the data unconditionally, like a concurrent writer, and returns the new version.
+func (r *MemoryResource) Set(data []byte) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.data = bytes.Clone(data)
+	r.version++
+	return strconv.Itoa(r.version)
+}
@@ -0,0 +1,204 @@
+package editor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+type failingResource struct {
+	*MemoryResource
+}
+
+func (r *failingResource) Update(context.Context, []byte, string) error {
+	return fmt.Errorf("server unavailable")
+}
+
+func TestValidatingEditor_EditResource(t *testing.T) {
+	store := NewMemoryResource([]byte("a: 1\n"))
+	e := NewValidatingEditor(&alwaysValidSchema{})
+	e.ErrorHeader = true
+	var shown []string
+	e.LaunchFn = func(command, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		shown = append(shown, string(data))
+		switch len(shown) {
+		case 1:
+			// someone else updates the resource while the editor is open
+			store.Set([]byte("a: 2\n"))
+			return os.WriteFile(file, []byte("a: 3\n"), 0600)
+		default:
+			return os.WriteFile(file, []byte("a: 4\n"), 0600)
+		}
+	}
+
+	data, err := e.EditResource(context.Background(), "prefix", store)
+	if err != nil {
+		t.Fatalf("ValidatingEditor.EditResource() error = %v", err)
+	}
+	if string(data) != "a: 4\n" {
+		t.Errorf("ValidatingEditor.EditResource() data = %q, want %q", data, "a: 4\n")
+	}
+	if got, version, _ := store.Get(context.Background()); string(got) != "a: 4\n" || version != "3" {
+		t.Errorf("ValidatingEditor.EditResource() stored %q at version %s", got, version)
+	}
+	if len(shown) != 2 {
+		t.Fatalf("ValidatingEditor.EditResource() launched %d times, want 2", len(shown))
+	}
+	for _, want := range []string{"# " + msgResourceConflict, "<<<<<<< edited\na: 3\n=======\na: 2\n>>>>>>> latest\n"} {
+		if !strings.Contains(shown[1], want) {
+			t.Errorf("ValidatingEditor.EditResource() reopened with %q, want it to contain %q", shown[1], want)
+		}
+	}
+}
+
+func TestValidatingEditor_EditResourceWithoutHeader(t *testing.T) {
+	store := NewMemoryResource([]byte("a: 1\nb: 1\nc: 1\n"))
+	e := NewValidatingEditor(&alwaysValidSchema{})
+	warnings := &bytes.Buffer{}
+	e.Reporter = NewTextReporter(strings.NewReader(""), io.Discard, warnings)
+	var shown []string
+	e.LaunchFn = func(command, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		shown = append(shown, string(data))
+		if len(shown) == 1 {
+			store.Set([]byte("a: 1\nb: 1\nc: 2\n"))
+			return os.WriteFile(file, []byte("a: 3\nb: 1\nc: 1\n"), 0600)
+		}
+		return nil
+	}
+	if _, err := e.EditResource(context.Background(), "prefix", store); err != nil {
+		t.Fatalf("ValidatingEditor.EditResource() error = %v", err)
+	}
+	if len(shown) != 2 || shown[1] != "a: 3\nb: 1\nc: 2\n" {
+		t.Errorf("ValidatingEditor.EditResource() shown = %q, want the merge without a header", shown)
+	}
+	for _, want := range []string{msgResourceConflict, msgReviewMerge} {
+		if !strings.Contains(warnings.String(), want) {
+			t.Errorf("ValidatingEditor.EditResource() warned %q, want it to contain %q", warnings, want)
+		}
+	}
+}
+
+func TestValidatingEditor_EditResourceMerged(t *testing.T) {
+	store := NewMemoryResource([]byte("a: 1\n"))
+	e := NewValidatingEditor(&alwaysValidSchema{})
+	e.ErrorHeader = true
+	var shown []string
+	e.LaunchFn = func(command, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		shown = append(shown, string(data))
+		if len(shown) == 1 {
+			// someone else writes without changing anything
+			store.Set([]byte("a: 1\n"))
+			return os.WriteFile(file, []byte("a: 2\n"), 0600)
+		}
+		return nil
+	}
+	data, err := e.EditResource(context.Background(), "prefix", store)
+	if err != nil {
+		t.Fatalf("ValidatingEditor.EditResource() error = %v", err)
+	}
+	if string(data) != "a: 2\n" {
+		t.Errorf("ValidatingEditor.EditResource() data = %q, want %q", data, "a: 2\n")
+	}
+	if len(shown) != 2 || !strings.Contains(shown[1], msgReviewMerge) {
+		t.Errorf("ValidatingEditor.EditResource() shown = %q, want a review of the merge", shown)
+	}
+}
+
+func TestValidatingEditor_EditResourceUpdateFailed(t *testing.T) {
+	store := &failingResource{NewMemoryResource([]byte("a: 1\n"))}
+	e := NewValidatingEditor(&alwaysValidSchema{})
+	e.ErrorHeader = true
+	var preserved string
+	e.PreserveFileFn = func(data []byte, file string, err error) ([]byte, string, error) {
+		preserved = file
+		return data, file, err
+	}
+	e.LaunchFn = func(command, file string) error {
+		return os.WriteFile(file, []byte("a: 2\n"), 0600)
+	}
+	data, err := e.EditResource(context.Background(), "prefix", store)
+	defer os.Remove(preserved)
+	if err == nil || errors.Is(err, ErrConflict) {
+		t.Errorf("ValidatingEditor.EditResource() error = %v, want update failure", err)
+	}
+	if string(data) != "a: 2\n" || preserved == "" {
+		t.Errorf("ValidatingEditor.EditResource() data = %q, preserved = %q, want edits preserved", data, preserved)
+	}
+}
+
+func TestMemoryResource(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryResource([]byte("a"))
+	_, version, _ := r.Get(ctx)
+	if err := r.Update(ctx, []byte("b"), version); err != nil {
+		t.Fatalf("MemoryResource.Update() error = %v", err)
+	}
+	if err := r.Update(ctx, []byte("c"), version); !errors.Is(err, ErrConflict) {
+		t.Errorf("MemoryResource.Update() error = %v, want %v", err, ErrConflict)
+	}
+	if data, _, _ := r.Get(ctx); string(data) != "b" {
+		t.Errorf("MemoryResource.Get() = %q, want %q", data, "b")
+	}
+}
+
+func TestValidatingEditor_EditResourceSameChange(t *testing.T) {
+	store := NewMemoryResource([]byte("a: 1\n"))
+	e := NewValidatingEditor(&alwaysValidSchema{})
+	e.ErrorHeader = true
+	launches := 0
+	e.LaunchFn = func(command, file string) error {
+		launches++
+		store.Set([]byte("a: 2\n"))
+		return os.WriteFile(file, []byte("a: 2\n"), 0600)
+	}
+	data, err := e.EditResource(context.Background(), "prefix", store)
+	if err != nil {
+		t.Fatalf("ValidatingEditor.EditResource() error = %v", err)
+	}
+	if string(data) != "a: 2\n" || launches != 1 {
+		t.Errorf("ValidatingEditor.EditResource() data = %q, launches = %d", data, launches)
+	}
+}
+
+func TestValidatingEditor_EditResourceThreeWay(t *testing.T) {
+	store := NewMemoryResource([]byte("a: 1\nb: 1\nc: 1\nd: 1\n"))
+	e := NewValidatingEditor(&alwaysValidSchema{})
+	e.ErrorHeader = true
+	var shown []string
+	e.LaunchFn = func(command, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		shown = append(shown, string(data))
+		if len(shown) == 1 {
+			store.Set([]byte("a: 1\nb: 1\nc: 1\nd: 2\n"))
+			return os.WriteFile(file, []byte("a: 2\nb: 1\nc: 1\nd: 1\n"), 0600)
+		}
+		return nil
+	}
+	data, err := e.EditResource(context.Background(), "prefix", store)
+	if err != nil {
+		t.Fatalf("ValidatingEditor.EditResource() error = %v", err)
+	}
+	if want := "a: 2\nb: 1\nc: 1\nd: 2\n"; string(data) != want {
+		t.Errorf("ValidatingEditor.EditResource() data = %q, want %q", data, want)
+	}
+}
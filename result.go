@@ -0,0 +1,65 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Result describes the outcome of an editing session.
+type Result struct {
+	// Data is the edited data, or nil if editing was cancelled.
+	Data []byte
+	// Path is the file that was edited. It is empty if the file was removed.
+	Path string
+	// Changed reports whether Data differs from the original data.
+	Changed bool
+	// Attempts is the number of times the editor was launched.
+	Attempts int
+	// Duration is how long the session took, including validation.
+	Duration time.Duration
+	// EditorCommand is the command the editor was launched with.
+	EditorCommand string
+	// Preserved reports whether the edits were left in Path after an error.
+	Preserved bool
+	// Diagnostics are the validation errors of the last attempt, if it failed.
+	Diagnostics []Diagnostic
+}
+
+// Diagnostic is a problem found in the edited data. Line and Column are
+// 1-based, or 0 if unknown.
+type Diagnostic struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	switch {
+	case d.Line > 0 && d.Column > 0:
+		return fmt.Sprintf("%d:%d: %s", d.Line, d.Column, d.Message)
+	case d.Line > 0:
+		return fmt.Sprintf("%d: %s", d.Line, d.Message)
+	}
+	return d.Message
+}
+
+// Diagnostics converts a validation error into diagnostics. Errors joined with
+// errors.Join are split up, and the position of a *PositionError is kept.
+func Diagnostics(err error) []Diagnostic {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var diags []Diagnostic
+		for _, err := range joined.Unwrap() {
+			diags = append(diags, Diagnostics(err)...)
+		}
+		return diags
+	}
+	var pe *PositionError
+	if errors.As(err, &pe) {
+		return []Diagnostic{{Line: pe.Line, Column: pe.Column, Message: pe.Err.Error()}}
+	}
+	return []Diagnostic{{Message: err.Error()}}
+}
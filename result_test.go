@@ -0,0 +1,133 @@
+package editor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []Diagnostic
+	}{
+		{name: "nil"},
+		{name: "plain", err: errors.New("invalid"), want: []Diagnostic{{Message: "invalid"}}},
+		{
+			name: "position",
+			err:  fmt.Errorf("decode: %w", &PositionError{Line: 2, Column: 5, Err: errors.New("unexpected comma")}),
+			want: []Diagnostic{{Line: 2, Column: 5, Message: "unexpected comma"}},
+		},
+		{
+			name: "joined",
+			err:  errors.Join(errors.New("first"), &PositionError{Line: 3, Column: 1, Err: errors.New("second")}),
+			want: []Diagnostic{{Message: "first"}, {Line: 3, Column: 1, Message: "second"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diagnostics(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diagnostics() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiagnostic_String(t *testing.T) {
+	tests := []struct {
+		d    Diagnostic
+		want string
+	}{
+		{d: Diagnostic{Line: 2, Column: 5, Message: "bad"}, want: "2:5: bad"},
+		{d: Diagnostic{Line: 2, Message: "bad"}, want: "2: bad"},
+		{d: Diagnostic{Message: "bad"}, want: "bad"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestBasicEditor_EditTempFile(t *testing.T) {
+	e := NewEditor()
+	e.Command = "fake-editor"
+	e.LaunchFn = func(command, file string) error {
+		return os.WriteFile(file, []byte("edited\n"), 0600)
+	}
+	res, err := e.EditTempFile(context.Background(), "test", strings.NewReader("original\n"))
+	if err != nil {
+		t.Fatalf("EditTempFile() error = %v", err)
+	}
+	defer os.Remove(res.Path)
+	if string(res.Data) != "edited\n" || !res.Changed || res.Attempts != 1 || res.EditorCommand != "fake-editor" {
+		t.Errorf("EditTempFile() = %+v", res)
+	}
+}
+
+func TestValidatingEditor_EditTempFile(t *testing.T) {
+	invalid := &PositionError{Line: 1, Column: 2, Err: errors.New("bad")}
+	tests := []struct {
+		name   string
+		schema Schema
+		edited []string
+		want   Result
+	}{
+		{
+			name:   "valid after retry",
+			schema: &compoundSchema{schemas: []Schema{errorSchema{invalid}, &alwaysValidSchema{}}},
+			edited: []string{"first\n", "second\n"},
+			want:   Result{Data: []byte("second\n"), Changed: true, Attempts: 2, EditorCommand: "fake-editor"},
+		},
+		{
+			name:   "unchanged",
+			schema: &alwaysValidSchema{},
+			edited: []string{"original\n"},
+			want:   Result{Attempts: 1, EditorCommand: "fake-editor"},
+		},
+		{
+			name:   "preserved with diagnostics",
+			schema: errorSchema{invalid},
+			edited: []string{"first\n", "first\n"},
+			want: Result{
+				Data: []byte("first\n"), Changed: true, Attempts: 2, EditorCommand: "fake-editor",
+				Preserved: true, Diagnostics: []Diagnostic{{Line: 1, Column: 2, Message: "bad"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewValidatingEditor(tt.schema)
+			e.Reporter = NopReporter{}
+			e.Command = "fake-editor"
+			editCount := 0
+			e.LaunchFn = func(command, file string) error {
+				err := os.WriteFile(file, []byte(tt.edited[editCount]), 0600)
+				editCount++
+				return err
+			}
+			res, _ := e.EditTempFile(context.Background(), "test", strings.NewReader("original\n"))
+			if res.Path != "" {
+				os.Remove(res.Path)
+			}
+			if (res.Path != "") != (tt.want.Data != nil) {
+				t.Errorf("EditTempFile() path = %q", res.Path)
+			}
+			res.Path, res.Duration = "", 0
+			if !reflect.DeepEqual(*res, tt.want) {
+				t.Errorf("EditTempFile() = %+v, want %+v", *res, tt.want)
+			}
+		})
+	}
+}
+
+type errorSchema struct{ err error }
+
+func (s errorSchema) ValidateBytes([]byte) error {
+	return s.err
+}
@@ -1,13 +1,14 @@
 package editor
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"time"
 )
 
 // ErrEditing represents an editing error
@@ -18,18 +19,14 @@ var (
 	msgCancelledNoValidChanges = "Edit cancelled, no valid changes were saved."
 	msgCancelledNoOrigChanges  = "Edit cancelled, no changes made."
 	msgCancelledEmptyFile      = "Edit cancelled, saved file was empty."
-	msgPreserveFileLocation    = "A copy of your changes has been stored to %s\n"
+	msgCancelledDiscarded      = "Edit cancelled, changes were discarded."
+	msgResourceConflict        = "The resource was changed while you were editing it."
+	msgResolveConflicts        = "Resolve the conflicts marked with <<<<<<< and >>>>>>> and save again."
+	msgReviewMerge             = "Your changes were merged with the latest version. Review them and save again."
+	msgPreserveFileLocation    = "A copy of your changes has been stored to %s"
+	msgDocument                = "Document %d:"
+	msgDocumentInvalid         = "Document %d, starting at line %d, failed validation"
 
-	defaultInvalidFn = func(e error) error {
-		fmt.Printf("%s: %v\n", msgValidationFailed, e)
-		return ErrEditing(errors.New(msgCancelledNoValidChanges))
-	}
-	defaultNoChangesFn    = func() (bool, error) { return true, ErrEditing(errors.New(msgCancelledNoOrigChanges)) }
-	defaultEmptyFileFn    = func() (bool, error) { return true, ErrEditing(errors.New(msgCancelledEmptyFile)) }
-	defaultPreserveFileFn = func(data []byte, file string, err error) ([]byte, string, error) {
-		fmt.Printf(msgPreserveFileLocation, file)
-		return data, file, err
-	}
 	defaultCommentChars = []string{"#", "//"}
 )
 
@@ -50,23 +47,102 @@ type ValidatingEditor struct {
 	// PreserveFileFn is called when a non-recoverable error has occurred and the users edits have been preserved in a temp file.
 	PreserveFileFn PreserveFileFn
 
+	// Reporter shows the messages of the default callbacks. Defaults to plain text on stdout.
+	Reporter Reporter
+	// Messages translates the messages shown to the user. Defaults to the catalog for the locale from the environment.
+	Messages Catalog
+
 	// CommentChars is a list of comment string prefixes for determining "empty" files. Defaults to "#" and "//".
+	// These are only recognized at the start of a line. It is ignored if CommentSyntax is set.
 	CommentChars []string
+	// CommentSyntax describes the comments of the edited format, like CStyleComments or XMLComments.
+	CommentSyntax *CommentSyntax
+	// EmptyPredicate replaces the comment syntax for determining "empty" files.
+	EmptyPredicate EmptyPredicate
+	// EqualFn decides whether the edited data is unchanged, both from the original data and between retries.
+	// Defaults to byte equality. See IgnoreTrailingWhitespace, IgnoreComments and JSONEqual.
+	EqualFn Comparator
+
+	// StripComments removes comments from the returned data. The schema sees the comments blanked out instead,
+	// so line numbers in its errors match the edited file.
+	StripComments bool
+
+	// ConfirmFn is called to review valid edits before they are accepted, for example with NewDiffConfirm.
+	// Editing continues where the user left off if they choose to edit again.
+	ConfirmFn ConfirmFn
+
+	// FileSchemas validate the files of LaunchTempDir, by a pattern matching their slash-separated path, like
+	// "*.json" or "config/*.yaml". The first pattern in lexical order wins. Other files are validated with Schema.
+	FileSchemas map[string]Schema
+
+	// MaxAttempts stops editing once the edited data failed validation this many times, like when
+	// the user gives up. The edits are preserved. Zero allows any number of attempts.
+	MaxAttempts int
+
+	// RejectConflictMarkers fails validation while the edited data contains conflict markers, like from Merge3.
+	RejectConflictMarkers bool
+
+	// ErrorHeader prepends the reason for reopening the editor, like the last validation error, as a comment block.
+	// Line numbers in the error are shifted to point at the lines below the block. The block is removed again
+	// before the edited data is compared and validated. It requires a comment syntax and is disabled by default.
+	ErrorHeader bool
+
+	// JSONC removes comments and trailing commas from the edited JSON before validation, so the Schema
+	// receives standard JSON with the line numbers of the edited file. The returned data is cleaned JSON.
+	JSONC bool
+
+	// Interaction decides whether the editor is launched. Defaults to Interactive.
+	Interaction Interaction
+	// Input holds the edited data when not interactive, like a file given on the command line. Defaults to stdin.
+	Input io.Reader
 }
 
 // NewValidatingEditor returns a new ValidatingEditor.
 //
 // This extends the BasicEditor with schema validation capabilities.
 func NewValidatingEditor(schema Schema) *ValidatingEditor {
-	return &ValidatingEditor{
-		BasicEditor:         NewEditor(),
-		Schema:              schema,
-		InvalidFn:           defaultInvalidFn,
-		OriginalUnchangedFn: defaultNoChangesFn,
-		EmptyFileFn:         defaultEmptyFileFn,
-		PreserveFileFn:      defaultPreserveFileFn,
-		CommentChars:        defaultCommentChars,
+	e := &ValidatingEditor{
+		BasicEditor:  NewEditor(),
+		Schema:       schema,
+		Reporter:     newDefaultReporter(),
+		Messages:     CatalogForLocale(LocaleFromEnv()),
+		CommentChars: defaultCommentChars,
 	}
+	e.InvalidFn = e.defaultInvalid
+	e.OriginalUnchangedFn = e.defaultNoChanges
+	e.EmptyFileFn = e.defaultEmptyFile
+	e.PreserveFileFn = e.defaultPreserveFile
+	return e
+}
+
+// defaultInvalid reports the validation error and cancels editing.
+func (e *ValidatingEditor) defaultInvalid(err error) error {
+	e.reporter().Error(fmt.Sprintf("%s: %v", e.Messages.Get(MsgValidationFailed), err))
+	return ErrEditing(errors.New(e.Messages.Get(MsgCancelledNoValidChanges)))
+}
+
+// defaultNoChanges cancels editing.
+func (e *ValidatingEditor) defaultNoChanges() (bool, error) {
+	return true, ErrEditing(errors.New(e.Messages.Get(MsgCancelledNoOrigChanges)))
+}
+
+// defaultEmptyFile cancels editing.
+func (e *ValidatingEditor) defaultEmptyFile() (bool, error) {
+	return true, ErrEditing(errors.New(e.Messages.Get(MsgCancelledEmptyFile)))
+}
+
+// defaultPreserveFile reports where the edits were preserved.
+func (e *ValidatingEditor) defaultPreserveFile(data []byte, file string, err error) ([]byte, string, error) {
+	e.reporter().Warn(e.Messages.Format(MsgPreserveFileLocationFmt, file))
+	return data, file, err
+}
+
+// reporter returns Reporter, or a NopReporter if it isn't set.
+func (e *ValidatingEditor) reporter() Reporter {
+	if e.Reporter == nil {
+		return NopReporter{}
+	}
+	return e.Reporter
 }
 
 // LaunchTempFile launches the users preferred editor on a temporary file.
@@ -81,100 +157,346 @@ func NewValidatingEditor(schema Schema) *ValidatingEditor {
 // The last byte of "obj" must be a newline to cancel editing if no changes are made.
 // (This is because many editors like vim automatically add a newline when saving.)
 func (e *ValidatingEditor) LaunchTempFile(prefix string, obj io.Reader) ([]byte, string, error) {
+	return e.LaunchTempFileContext(context.Background(), prefix, obj)
+}
+
+// LaunchTempFileContext is like LaunchTempFile but stops before (re)launching
+// the editor once the context is done. Edits made so far are preserved.
+func (e *ValidatingEditor) LaunchTempFileContext(ctx context.Context, prefix string, obj io.Reader) ([]byte, string, error) {
+	res, err := e.EditTempFile(ctx, prefix, obj)
+	return res.Data, res.Path, err
+}
+
+// EditTempFile is like LaunchTempFileContext but describes the session with a
+// Result, which is never nil.
+func (e *ValidatingEditor) EditTempFile(ctx context.Context, prefix string, obj io.Reader) (*Result, error) {
+	original, err := io.ReadAll(obj)
+	if err != nil {
+		return &Result{EditorCommand: e.Command}, err
+	}
+	return e.launchTempFile(ctx, prefix, original, original, nil)
+}
+
+// launchTempFile runs the editing loop. The editor is first opened with initial,
+// explained by a header for reason if it isn't nil, or else by a warning, and edits are compared with
+// original to detect unchanged files.
+func (e *ValidatingEditor) launchTempFile(ctx context.Context, prefix string, original, initial []byte, reason []string) (*Result, error) {
+	if !e.interactive() {
+		return e.editInput(ctx, original)
+	}
+
 	editor := e.BasicEditor.clone()
+	res := &Result{EditorCommand: editor.Command}
+	start := time.Now()
+	defer func() { res.Duration = time.Since(start) }()
 
 	var (
-		prevErr  error
-		original []byte
-		edited   []byte
-		file     string
-		err      error
+		prevErr error
+		edited  = initial
+		header  []byte
+		file    string
+		err     error
 	)
 
-	originalObj, err := io.ReadAll(obj)
-	if err != nil {
-		return nil, "", err
+	preserve := func(err error) (*Result, error) {
+		res.Data, res.Path, err = e.preserve(ctx, edited, file, err)
+		res.Preserved = res.Path != ""
+		res.Changed = res.Data != nil && !e.equal(original, res.Data)
+		return res, err
+	}
+	cancel := func(reason string, err error) (*Result, error) {
+		os.Remove(file)
+		e.cancelled(ctx, reason, err)
+		return res, err
 	}
 
 	// loop until we succeed or cancel editing
 	for {
-		// Create the file to edit
-		buf := &bytes.Buffer{}
-		if prevErr == nil {
-			buf.Write(originalObj)
-			original = buf.Bytes()
-		} else {
-			// Preserve the edited file
-			buf.Write(edited)
+		// Create the file to edit, explaining why it was reopened, and
+		// otherwise continuing where the user left off
+		switch {
+		case prevErr != nil:
+			header = e.errorHeader(e.commentSyntax(), prevErr)
+		case reason != nil:
+			// without a header, tell the user why the contents changed
+			if header = e.header(reason); header == nil {
+				e.reporter().Warn(strings.Join(reason, "\n"))
+			}
+			reason = nil
+		default:
+			header = nil
 		}
+		buf := &bytes.Buffer{}
+		buf.Write(header)
+		buf.Write(edited)
 
 		// Launch the editor
 		editedDiff := edited
-		edited, file, err = editor.LaunchTempFile(prefix, buf)
+		res.Attempts++
+		edited, file, err = editor.LaunchTempFileContext(ctx, prefix, buf)
 		if err != nil {
-			return e.PreserveFileFn(edited, file, err)
+			return preserve(err)
 		}
+		var stripped bool
+		edited, stripped = stripErrorHeader(edited, header)
 
 		// If we're retrying the loop because of an error, and no change was made in the file, short-circuit
-		if prevErr != nil && bytes.Equal(editedDiff, edited) {
-			return e.PreserveFileFn(edited, file, e.InvalidFn(prevErr))
+		if prevErr != nil && e.equal(editedDiff, edited) {
+			return preserve(e.InvalidFn(prevErr))
 		}
+		res.Diagnostics = nil
 
 		// Compare contents for changes
-		if bytes.Equal(original, edited) {
-			cancel, err := e.OriginalUnchangedFn()
-			if cancel {
-				os.Remove(file)
-				return nil, "", err
+		if e.equal(original, edited) {
+			cancelled, err := e.OriginalUnchangedFn()
+			if cancelled {
+				return cancel("unchanged", err)
 			}
 		}
 
 		// Check for an (effectively) empty file
 		empty, err := e.isEmpty(edited)
 		if err != nil {
-			return e.PreserveFileFn(edited, file, err)
+			return preserve(err)
 		}
 		if empty {
-			cancel, err := e.EmptyFileFn()
-			if cancel {
-				os.Remove(file)
-				return nil, "", err
+			cancelled, err := e.EmptyFileFn()
+			if cancelled {
+				return cancel("empty", err)
 			}
 		}
 
 		// Apply validation
-		err = e.Schema.ValidateBytes(edited)
+		result, validated := e.clean(edited)
+		err = e.validate(validated)
 		if err != nil {
+			e.validationFailed(ctx, res.Attempts, err)
+			res.Diagnostics = Diagnostics(err)
+			if e.MaxAttempts > 0 && res.Attempts >= e.MaxAttempts {
+				return preserve(e.InvalidFn(err))
+			}
 			prevErr = err
 			os.Remove(file)
 			continue
 		}
 
-		return edited, file, nil
+		// Let the user review their edits
+		if e.ConfirmFn != nil {
+			action, err := e.ConfirmFn(original, result)
+			if err != nil {
+				return preserve(err)
+			}
+			switch action {
+			case ConfirmEditAgain:
+				prevErr = nil
+				os.Remove(file)
+				continue
+			case ConfirmDiscard:
+				return cancel("discarded", ErrEditing(errors.New(e.Messages.Get(MsgCancelledDiscarded))))
+			}
+		}
+
+		// Leave the file as the caller would expect it, matching the returned data
+		if stripped || !bytes.Equal(result, edited) {
+			if err := os.WriteFile(file, result, 0600); err != nil {
+				return preserve(err)
+			}
+		}
+
+		e.succeeded(ctx, file, res.Attempts)
+		res.Data, res.Path = result, file
+		res.Changed = !e.equal(original, result)
+		return res, nil
 	}
 }
 
-// isEmpty returns true if the file doesn't have any uncommented lines (ignoring whitespace)
-func (e *ValidatingEditor) isEmpty(data []byte) (bool, error) {
-	empty := true
-	scanner := bufio.NewScanner(bytes.NewBuffer(data))
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" {
-			commented := false
-			for _, c := range e.CommentChars {
-				if strings.HasPrefix(line, c) {
-					commented = true
-				}
-			}
-			if !commented {
-				empty = false
-				break
+// clean applies StripComments and JSONC to edited data, returning the data to
+// return and the data to validate.
+func (e *ValidatingEditor) clean(edited []byte) (result, validated []byte) {
+	return e.cleanWith(e.commentSyntax(), e.JSONC, edited)
+}
+
+// cleanWith is like clean with the given comment syntax and JSONC. The data to
+// validate keeps the lines of the edited data, so errors point at what the user sees.
+func (e *ValidatingEditor) cleanWith(syntax CommentSyntax, jsonc bool, edited []byte) (result, validated []byte) {
+	result, validated = edited, edited
+	if e.StripComments {
+		result, validated = syntax.Strip(edited), syntax.blank(edited)
+	}
+	if jsonc {
+		validated = StripJSONC(validated)
+		result = CleanJSONC(result)
+	}
+	return result, validated
+}
+
+// validate checks for conflict markers if enabled, and then applies the Schema.
+func (e *ValidatingEditor) validate(data []byte) error {
+	if e.RejectConflictMarkers && HasConflictMarkers(data) {
+		return ErrConflictMarkers
+	}
+	return e.Schema.ValidateBytes(data)
+}
+
+// rejectingConflictMarkers returns a copy of the editor with RejectConflictMarkers set.
+func (e *ValidatingEditor) rejectingConflictMarkers() *ValidatingEditor {
+	c := *e
+	c.RejectConflictMarkers = true
+	return &c
+}
+
+// equal compares data with EqualFn, or bytes.Equal.
+func (e *ValidatingEditor) equal(a, b []byte) bool {
+	if e.EqualFn != nil {
+		return e.EqualFn(a, b)
+	}
+	return bytes.Equal(a, b)
+}
+
+// commentSyntax returns CommentSyntax, or else line comments from CommentChars.
+func (e *ValidatingEditor) commentSyntax() CommentSyntax {
+	if e.CommentSyntax != nil {
+		return *e.CommentSyntax
+	}
+	return CommentSyntax{Line: e.CommentChars, LineStart: true}
+}
+
+// validationErrorLines describes a validation error for the header.
+func (e *ValidatingEditor) validationErrorLines(err error) []string {
+	lines := []string{e.Messages.Get(MsgValidationFailed) + ":"}
+	for _, line := range strings.Split(strings.TrimRight(err.Error(), "\n"), "\n") {
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	return lines
+}
+
+// header returns the lines as a comment block, or nil if ErrorHeader is disabled
+// or there is no comment syntax to use.
+func (e *ValidatingEditor) header(lines []string) []byte {
+	return e.headerWith(e.commentSyntax(), lines)
+}
+
+// headerWith is like header, with the comment syntax to use.
+func (e *ValidatingEditor) headerWith(syntax CommentSyntax, lines []string) []byte {
+	if !e.ErrorHeader {
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	switch {
+	case len(syntax.Line) > 0:
+		c := syntax.Line[0]
+		for _, line := range lines {
+			fmt.Fprintf(buf, "%s %s\n", c, line)
+		}
+		fmt.Fprintf(buf, "%s\n", c)
+	case len(syntax.Block) > 0:
+		fmt.Fprintf(buf, "%s\n", syntax.Block[0].Start)
+		for _, line := range lines {
+			fmt.Fprintf(buf, "  %s\n", line)
+		}
+		fmt.Fprintf(buf, "%s\n", syntax.Block[0].End)
+	default:
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// errorHeader returns the header for a validation error. Lines of its
+// PositionErrors are shifted by the length of the header, so they point at the
+// lines of the reopened file.
+func (e *ValidatingEditor) errorHeader(syntax CommentSyntax, err error) []byte {
+	header := e.headerWith(syntax, e.validationErrorLines(err))
+	if n := bytes.Count(header, []byte("\n")); n > 0 {
+		header = e.headerWith(syntax, e.validationErrorLines(shiftLines(err, n)))
+	}
+	return header
+}
+
+// shiftLines returns an error with the text of err, where the lines of its
+// PositionErrors are shifted by n.
+func shiftLines(err error, n int) error {
+	var pairs []string
+	for _, pe := range positionErrors(err) {
+		shifted := *pe
+		shifted.Line += n
+		pairs = append(pairs, pe.Error(), shifted.Error())
+	}
+	if len(pairs) == 0 {
+		return err
+	}
+	return errors.New(strings.NewReplacer(pairs...).Replace(err.Error()))
+}
+
+// positionErrors returns the outermost PositionErrors wrapped by err.
+func positionErrors(err error) []*PositionError {
+	switch err := err.(type) {
+	case *PositionError:
+		return []*PositionError{err}
+	case interface{ Unwrap() error }:
+		return positionErrors(err.Unwrap())
+	case interface{ Unwrap() []error }:
+		var pes []*PositionError
+		for _, err := range err.Unwrap() {
+			pes = append(pes, positionErrors(err)...)
+		}
+		return pes
+	}
+	return nil
+}
+
+// stripErrorHeader removes the header from the start of data. The whole block
+// is removed as long as it is closed like the header and still holds one of its
+// lines, even if the user changed others. Otherwise the leading lines which
+// still match the header lines, in order, are removed. Returns whether anything
+// was removed.
+func stripErrorHeader(data, header []byte) ([]byte, bool) {
+	headerLines := strings.Split(strings.TrimSuffix(string(header), "\n"), "\n")
+	if len(headerLines) < 2 {
+		return data, false
+	}
+	// line comment headers are closed by a bare comment marker, block comment
+	// headers by the end of the block
+	end := headerLines[len(headerLines)-1]
+	lineComments := strings.HasPrefix(headerLines[0], end)
+	content := map[string]bool{}
+	for i, line := range headerLines[:len(headerLines)-1] {
+		if lineComments || i > 0 {
+			content[line] = true
+		}
+	}
+
+	lines := splitLines(data)
+	found := false
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t\r\n")
+		if line == end && (i > 0 || lineComments) {
+			if found {
+				return []byte(strings.Join(lines[i+1:], "")), true
 			}
+			break
+		}
+		if lineComments && !strings.HasPrefix(line, end) || !lineComments && i == 0 && line != headerLines[0] {
+			break
 		}
+		found = found || content[line]
 	}
-	if err := scanner.Err(); err != nil {
-		return false, err
+
+	// the block isn't closed anymore, so only remove the lines still matching
+	n, found := 0, false
+	for n < len(lines) && n < len(headerLines) && strings.TrimRight(lines[n], " \t\r\n") == headerLines[n] {
+		found = found || content[headerLines[n]]
+		n++
+	}
+	if n == 0 || !found {
+		return data, false
+	}
+	return []byte(strings.Join(lines[n:], "")), true
+}
+
+// isEmpty returns true if the file doesn't have any uncommented content (ignoring whitespace)
+func (e *ValidatingEditor) isEmpty(data []byte) (bool, error) {
+	if e.EmptyPredicate != nil {
+		return e.EmptyPredicate(data)
 	}
-	return empty, nil
+	return e.commentSyntax().IsEmpty(data), nil
 }
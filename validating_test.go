@@ -2,9 +2,12 @@ package editor
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -33,7 +36,9 @@ func (s *compoundSchema) ValidateBytes(data []byte) error {
 
 func TestValidatingEditor_LaunchTempFile(t *testing.T) {
 	type fields struct {
-		Schema Schema
+		Schema      Schema
+		EqualFn     Comparator
+		MaxAttempts int
 	}
 	type args struct {
 		prefix   string
@@ -63,6 +68,36 @@ func TestValidatingEditor_LaunchTempFile(t *testing.T) {
 			wantErr:       msgCancelledNoOrigChanges,
 			wantPreserved: false,
 		},
+		{
+			name: "cancel on original semantically unchanged",
+			fields: fields{
+				Schema:  &alwaysInvalidSchema{},
+				EqualFn: IgnoreTrailingWhitespace,
+			},
+			args: args{
+				original: "original data\n",
+				edited:   []string{"original data\r\n\n"},
+			},
+			wantData:      "",
+			wantFile:      false,
+			wantErr:       msgCancelledNoOrigChanges,
+			wantPreserved: false,
+		},
+		{
+			name: "cancel on invalid edit and then semantically unchanged edit",
+			fields: fields{
+				Schema:  &alwaysInvalidSchema{},
+				EqualFn: IgnoreTrailingWhitespace,
+			},
+			args: args{
+				original: "original data",
+				edited:   []string{"invalid data", "invalid data  \n"},
+			},
+			wantData:      "invalid data  \n",
+			wantFile:      true,
+			wantErr:       "invalid " + msgCancelledNoValidChanges,
+			wantPreserved: true,
+		},
 		{
 			name: "cancel on invalid edit and then unchanged edit",
 			fields: fields{
@@ -77,6 +112,21 @@ func TestValidatingEditor_LaunchTempFile(t *testing.T) {
 			wantErr:       "invalid " + msgCancelledNoValidChanges,
 			wantPreserved: true,
 		},
+		{
+			name: "cancel after max attempts",
+			fields: fields{
+				Schema:      &alwaysInvalidSchema{},
+				MaxAttempts: 2,
+			},
+			args: args{
+				original: "original data",
+				edited:   []string{"invalid data", "more invalid data"},
+			},
+			wantData:      "more invalid data",
+			wantFile:      true,
+			wantErr:       "invalid " + msgCancelledNoValidChanges,
+			wantPreserved: true,
+		},
 		{
 			name: "cancel on invalid edit, different invalid edit, and then unchanged edit",
 			fields: fields{
@@ -171,6 +221,8 @@ func TestValidatingEditor_LaunchTempFile(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			e := NewValidatingEditor(tt.fields.Schema)
+			e.EqualFn = tt.fields.EqualFn
+			e.MaxAttempts = tt.fields.MaxAttempts
 			e.InvalidFn = func(e error) error { return fmt.Errorf("%s %s", e.Error(), msgCancelledNoValidChanges) }
 			preserved := false
 			e.PreserveFileFn = func(data []byte, file string, err error) ([]byte, string, error) {
@@ -209,8 +261,10 @@ func TestValidatingEditor_LaunchTempFile(t *testing.T) {
 
 func TestValidatingEditor_isEmpty(t *testing.T) {
 	type args struct {
-		comments []string
-		data     []byte
+		comments  []string
+		syntax    *CommentSyntax
+		predicate EmptyPredicate
+		data      []byte
 	}
 	tests := []struct {
 		name    string
@@ -297,6 +351,38 @@ func TestValidatingEditor_isEmpty(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "comment syntax with block comments",
+			args: args{
+				syntax: &CStyleComments,
+				data:   []byte("/* hello\nworld */"),
+			},
+			want: true,
+		},
+		{
+			name: "comment syntax replaces comment chars",
+			args: args{
+				syntax: &XMLComments,
+				data:   []byte("# hello"),
+			},
+			want: false,
+		},
+		{
+			name: "custom predicate",
+			args: args{
+				predicate: func(data []byte) (bool, error) { return string(data) == "{}", nil },
+				data:      []byte("{}"),
+			},
+			want: true,
+		},
+		{
+			name: "custom predicate error",
+			args: args{
+				predicate: func(data []byte) (bool, error) { return false, fmt.Errorf("failed") },
+				data:      []byte("{}"),
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -304,6 +390,8 @@ func TestValidatingEditor_isEmpty(t *testing.T) {
 			if tt.args.comments != nil {
 				e.CommentChars = tt.args.comments
 			}
+			e.CommentSyntax = tt.args.syntax
+			e.EmptyPredicate = tt.args.predicate
 			e.LaunchFn = func(command, file string) error {
 				return os.WriteFile(file, tt.args.data, 0777)
 			}
@@ -318,3 +406,211 @@ func TestValidatingEditor_isEmpty(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatingEditor_ErrorHeader(t *testing.T) {
+	e := NewValidatingEditor(&compoundSchema{
+		schemas: []Schema{&alwaysInvalidSchema{}, &alwaysValidSchema{}},
+	})
+	e.ErrorHeader = true
+	var shown []string
+	e.LaunchFn = func(command, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		shown = append(shown, string(data))
+		// keep whatever was shown and append a line, like a user would
+		return os.WriteFile(file, append(data, "more\n"...), 0600)
+	}
+	data, file, err := e.LaunchTempFile("prefix", bytes.NewBufferString("original\n"))
+	defer os.Remove(file)
+	if err != nil {
+		t.Fatalf("ValidatingEditor.LaunchTempFile() error = %v", err)
+	}
+	wantShown := []string{
+		"original\n",
+		"# " + msgValidationFailed + ":\n# invalid\n#\noriginal\nmore\n",
+	}
+	if !reflect.DeepEqual(shown, wantShown) {
+		t.Errorf("ValidatingEditor.LaunchTempFile() shown = %q, want %q", shown, wantShown)
+	}
+	if want := "original\nmore\nmore\n"; string(data) != want {
+		t.Errorf("ValidatingEditor.LaunchTempFile() data = %q, want %q", data, want)
+	}
+	if disk, _ := os.ReadFile(file); !bytes.Equal(disk, data) {
+		t.Errorf("ValidatingEditor.LaunchTempFile() disk = %q, want %q", disk, data)
+	}
+}
+
+func Test_stripErrorHeader(t *testing.T) {
+	lineHeader := "# failed:\n# line 4: bad\n#\n"
+	blockHeader := "<!--\n  failed:\n  line 5: bad\n-->\n"
+	tests := []struct {
+		name   string
+		data   string
+		header string
+		want   string
+	}{
+		{name: "unchanged", data: lineHeader + "# own\ndata\n", header: lineHeader, want: "# own\ndata\n"},
+		{name: "first line changed", data: "# failed: fixed\n# line 4: bad\n#\ndata\n", header: lineHeader, want: "data\n"},
+		{name: "line added", data: "# failed:\n# note\n# line 4: bad\n#\ndata\n", header: lineHeader, want: "data\n"},
+		{name: "all lines changed", data: "# a\n# b\n#\ndata\n", header: lineHeader, want: "# a\n# b\n#\ndata\n"},
+		{name: "closing line removed", data: "# failed:\n# line 4: bad\ndata\n", header: lineHeader, want: "data\n"},
+		{name: "removed", data: "data\n", header: lineHeader, want: "data\n"},
+		{name: "block changed", data: "<!--\n  failed: fixed\n  line 5: bad\n-->\n<root/>\n", header: blockHeader, want: "<root/>\n"},
+		{name: "block removed", data: "<!--\n  own\n-->\n<root/>\n", header: blockHeader, want: "<!--\n  own\n-->\n<root/>\n"},
+		{name: "no header", data: "data\n", header: "", want: "data\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, stripped := stripErrorHeader([]byte(tt.data), []byte(tt.header))
+			if string(got) != tt.want || stripped != (tt.want != tt.data) {
+				t.Errorf("stripErrorHeader() = %q, %v, want %q", got, stripped, tt.want)
+			}
+		})
+	}
+}
+
+func Test_shiftLines(t *testing.T) {
+	err := fmt.Errorf("decoding: %w", errors.Join(
+		&PositionError{Line: 3, Column: 15, Err: errors.New("bad port")},
+		&PositionError{Line: 1, Err: errors.New("bad name")},
+		errors.New("no position"),
+	))
+	want := "decoding: line 6, column 15: bad port\nline 4: bad name\nno position"
+	if got := shiftLines(err, 3).Error(); got != want {
+		t.Errorf("shiftLines() = %q, want %q", got, want)
+	}
+}
+
+func TestValidatingEditor_ErrorHeaderDisabled(t *testing.T) {
+	e := NewValidatingEditor(&compoundSchema{
+		schemas: []Schema{&alwaysInvalidSchema{}, &alwaysValidSchema{}},
+	})
+	var shown []string
+	e.LaunchFn = func(command, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		shown = append(shown, string(data))
+		return os.WriteFile(file, append(data, "more\n"...), 0600)
+	}
+	_, file, err := e.LaunchTempFile("prefix", bytes.NewBufferString("original\n"))
+	defer os.Remove(file)
+	if err != nil {
+		t.Fatalf("ValidatingEditor.LaunchTempFile() error = %v", err)
+	}
+	if want := []string{"original\n", "original\nmore\n"}; !reflect.DeepEqual(shown, want) {
+		t.Errorf("ValidatingEditor.LaunchTempFile() shown = %q, want %q", shown, want)
+	}
+}
+
+type jsonSchema struct{}
+
+func (s *jsonSchema) ValidateBytes(data []byte) error {
+	if !json.Valid(data) {
+		return fmt.Errorf("invalid JSON")
+	}
+	return nil
+}
+
+func TestValidatingEditor_JSONC(t *testing.T) {
+	e := NewValidatingEditor(&jsonSchema{})
+	e.JSONC = true
+	e.LaunchFn = func(command, file string) error {
+		return os.WriteFile(file, []byte("{\n  // comment\n  \"a\": 1,\n}\n"), 0600)
+	}
+	data, file, err := e.LaunchTempFile("prefix", bytes.NewBufferString("{}\n"))
+	defer os.Remove(file)
+	if err != nil {
+		t.Fatalf("ValidatingEditor.LaunchTempFile() error = %v", err)
+	}
+	if want := "{\n  \"a\": 1\n}\n"; string(data) != want {
+		t.Errorf("ValidatingEditor.LaunchTempFile() data = %q, want %q", data, want)
+	}
+	if disk, _ := os.ReadFile(file); !bytes.Equal(disk, data) {
+		t.Errorf("ValidatingEditor.LaunchTempFile() disk = %q, want %q", disk, data)
+	}
+}
+
+// keyLinesSchema requires every non-blank line to start with "key:".
+type keyLinesSchema struct{}
+
+func (keyLinesSchema) ValidateBytes(data []byte) error {
+	for i, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "key:") {
+			return &PositionError{Line: i + 1, Err: fmt.Errorf("unexpected %q", line)}
+		}
+	}
+	return nil
+}
+
+func TestValidatingEditor_StripComments(t *testing.T) {
+	e := NewValidatingEditor(keyLinesSchema{})
+	e.CommentSyntax = &HashComments
+	e.StripComments = true
+	e.ErrorHeader = true
+	e.Reporter = NopReporter{}
+	edits := []string{"# please edit\n\nbad # note\n", "# please edit\nkey: value # note\n"}
+	var shown string
+	e.LaunchFn = func(command, file string) error {
+		data, _ := os.ReadFile(file)
+		shown = string(data)
+		err := os.WriteFile(file, []byte(edits[0]), 0600)
+		edits = edits[1:]
+		return err
+	}
+	data, file, err := e.LaunchTempFile("prefix", bytes.NewBufferString("key: original\n"))
+	defer os.Remove(file)
+	if err != nil {
+		t.Fatalf("ValidatingEditor.LaunchTempFile() error = %v", err)
+	}
+	if want := "key: value\n"; string(data) != want {
+		t.Errorf("ValidatingEditor.LaunchTempFile() data = %q, want %q", data, want)
+	}
+	// the error points at the line in the file as edited, shifted past the header
+	header, _, _ := strings.Cut(shown, "# please edit")
+	if want := fmt.Sprintf("# line %d: unexpected \"bad\"", strings.Count(header, "\n")+3); !strings.Contains(shown, want) {
+		t.Errorf("ValidatingEditor.LaunchTempFile() shown %q, want it to contain %q", shown, want)
+	}
+}
+
+func TestValidatingEditor_ErrorHeaderBlockComments(t *testing.T) {
+	e := NewValidatingEditor(&compoundSchema{
+		schemas: []Schema{&alwaysInvalidSchema{}, &alwaysValidSchema{}},
+	})
+	e.ErrorHeader = true
+	e.CommentSyntax = &XMLComments
+	var shown []string
+	e.LaunchFn = func(command, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		shown = append(shown, string(data))
+		return os.WriteFile(file, append(data, "<more/>\n"...), 0600)
+	}
+	data, file, err := e.LaunchTempFile("prefix", bytes.NewBufferString("<root/>\n"))
+	defer os.Remove(file)
+	if err != nil {
+		t.Fatalf("ValidatingEditor.LaunchTempFile() error = %v", err)
+	}
+	if want := "<!--\n  " + msgValidationFailed + ":\n  invalid\n-->\n<root/>\n<more/>\n"; shown[1] != want {
+		t.Errorf("ValidatingEditor.LaunchTempFile() shown = %q, want %q", shown[1], want)
+	}
+	if want := "<root/>\n<more/>\n<more/>\n"; string(data) != want {
+		t.Errorf("ValidatingEditor.LaunchTempFile() data = %q, want %q", data, want)
+	}
+}
+
+type prefixSchema struct {
+	prefix string
+}
+
+func (s *prefixSchema) ValidateBytes(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(s.prefix)) {
+		return fmt.Errorf("data missing prefix %q", s.prefix)
+	}
+	return nil
+}